@@ -0,0 +1,17 @@
+data "eventline_project" "main" {
+  name = "main"
+}
+
+data "eventline_events" "failed" {
+  project_id = data.eventline_project.main.id
+
+  connector = "github"
+  name      = "push"
+}
+
+action "eventline_replay_event" "example" {
+  config {
+    project_id = data.eventline_project.main.id
+    event_id   = data.eventline_events.failed.elements[0].id
+  }
+}
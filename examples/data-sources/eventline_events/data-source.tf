@@ -0,0 +1,11 @@
+data "eventline_project" "main" {
+  name = "main"
+}
+
+data "eventline_events" "example" {
+  project_id = data.eventline_project.main.id
+
+  connector = "github"
+  name      = "push"
+  after     = "2026-01-01T00:00:00Z"
+}
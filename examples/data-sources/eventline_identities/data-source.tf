@@ -5,3 +5,11 @@ data "eventline_project" "main" {
 data "eventline_identities" "example" {
   project_id = data.eventline_project.main.id
 }
+
+data "eventline_identities" "github" {
+  project_id = data.eventline_project.main.id
+
+  connector    = "github"
+  include_data = true
+  status       = "ready"
+}
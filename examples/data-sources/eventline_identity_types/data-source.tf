@@ -0,0 +1,3 @@
+data "eventline_identity_types" "github" {
+  connector = "github"
+}
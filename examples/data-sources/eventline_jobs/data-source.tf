@@ -5,3 +5,11 @@ data "eventline_project" "main" {
 data "eventline_jobs" "example" {
   project_id = data.eventline_project.main.id
 }
+
+data "eventline_jobs" "github" {
+  project_id = data.eventline_project.main.id
+
+  disabled          = false
+  name_regex        = "^deploy-"
+  trigger_connector = "github"
+}
@@ -1,3 +1,7 @@
 data "eventline_project" "main" {
   name = "main"
 }
+
+data "eventline_project" "by_id" {
+  id = var.project_id
+}
@@ -1,2 +1,7 @@
 data "eventline_projects" "example" {
 }
+
+data "eventline_projects" "teams" {
+  include_counts = true
+  name_prefix    = "team-"
+}
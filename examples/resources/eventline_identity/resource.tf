@@ -10,3 +10,15 @@ resource "eventline_identity" "example" {
   data      = jsonencode({ "key" = "test" })
   type      = "api_key"
 }
+
+resource "eventline_identity" "github" {
+  name       = "github"
+  project_id = data.eventline_project.main.id
+
+  connector = "github"
+  type      = "token"
+  github_token = {
+    token    = var.github_token
+    username = var.github_username
+  }
+}
@@ -0,0 +1 @@
+terraform import eventline_job_set.test <project_id>
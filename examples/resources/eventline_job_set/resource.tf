@@ -0,0 +1,12 @@
+data "eventline_project" "main" {
+  name = "main"
+}
+
+resource "eventline_job_set" "example" {
+  project_id = data.eventline_project.main.id
+
+  jobs = {
+    for f in fileset("${path.module}/jobs", "*.yaml") :
+    trimsuffix(f, ".yaml") => jsonencode(yamldecode(file("${path.module}/jobs/${f}")))
+  }
+}
@@ -0,0 +1 @@
+terraform import eventline_job_state.test <project_id>/<job_name>
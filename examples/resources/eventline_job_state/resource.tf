@@ -0,0 +1,10 @@
+data "eventline_project" "main" {
+  name = "main"
+}
+
+resource "eventline_job_state" "example" {
+  project_id = data.eventline_project.main.id
+  name       = "deploy-production"
+
+  disabled = true
+}
@@ -1,3 +1,10 @@
 resource "eventline_project" "example" {
   name = "example"
 }
+
+resource "eventline_project" "sandbox" {
+  name = "sandbox"
+
+  deletion_protection = false
+  force_destroy       = true
+}
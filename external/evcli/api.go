@@ -10,10 +10,19 @@ import (
 )
 
 type APIError struct {
-	Message string          `json:"error"`
-	Code    string          `json:"code,omitempty"`
-	RawData json.RawMessage `json:"data,omitempty"`
-	Data    interface{}     `json:"-"`
+	Message    string          `json:"error"`
+	Code       string          `json:"code,omitempty"`
+	RawData    json.RawMessage `json:"data,omitempty"`
+	Data       interface{}     `json:"-"`
+	StatusCode int             `json:"-"`
+}
+
+// ResponseError is returned when the server replies with an error status and
+// a body which is not an eventline api error, usually because the endpoint is
+// not an eventline api or is behind a proxy which rejected the request.
+type ResponseError struct {
+	StatusCode int
+	Body       []byte
 }
 
 type InvalidRequestBodyError struct {
@@ -24,6 +33,11 @@ func (err APIError) Error() string {
 	return err.Message
 }
 
+func (err ResponseError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s",
+		err.StatusCode, string(err.Body))
+}
+
 func (err *APIError) UnmarshalJSON(data []byte) error {
 	type APIError2 APIError
 
@@ -7,13 +7,16 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/exograd/eventline/pkg/eventline"
 )
 
 type Client struct {
 	APIKey    string
+	Headers   map[string]string
 	ProjectId *eventline.Id
+	UserAgent string
 
 	httpClient *http.Client
 
@@ -61,6 +64,14 @@ func (c *Client) SendRequest(method string, relURI *url.URL, body, dest interfac
 		return fmt.Errorf("cannot create request: %w", err)
 	}
 
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
 	if c.APIKey != "" {
 		req.Header.Set("Authorization", "Bearer "+c.APIKey)
 	}
@@ -84,12 +95,12 @@ func (c *Client) SendRequest(method string, relURI *url.URL, body, dest interfac
 		var apiErr APIError
 
 		err := json.Unmarshal(resBody, &apiErr)
-		if err == nil {
+		if err == nil && apiErr.Message != "" {
+			apiErr.StatusCode = res.StatusCode
 			return &apiErr
 		}
 
-		return fmt.Errorf("request failed with status %d: %s",
-			res.StatusCode, string(resBody))
+		return &ResponseError{StatusCode: res.StatusCode, Body: resBody}
 	}
 
 	if dest != nil {
@@ -137,6 +148,20 @@ func (c *Client) FetchProjects() (eventline.Projects, error) {
 	return projects, nil
 }
 
+func (c *Client) FetchProjectPage(cursor eventline.Cursor) (*ProjectPage, error) {
+	uri := NewURL("projects")
+	uri.RawQuery = cursor.Query().Encode()
+
+	var page ProjectPage
+
+	err := c.SendRequest("GET", uri, nil, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
 func (c *Client) FetchProjectById(id eventline.Id) (*eventline.Project, error) {
 	uri := NewURL("projects", "id", id.String())
 
@@ -228,6 +253,19 @@ func (c *Client) FetchIdentityById(id eventline.Id) (*Identity, error) {
 	return &identity, nil
 }
 
+func (c *Client) FetchIdentityByName(name string) (*Identity, error) {
+	uri := NewURL("identities", "name", name)
+
+	var identity Identity
+
+	err := c.SendRequest("GET", uri, nil, &identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
 func (c *Client) UpdateIdentity(identity *Identity) error {
 	uri := NewURL("identities", "id", identity.Id.String())
 
@@ -240,8 +278,69 @@ func (c *Client) DeleteIdentity(id eventline.Id) error {
 	return c.SendRequest("DELETE", uri, nil, nil)
 }
 
-func (c *Client) ReplayEvent(id string) (*eventline.Event, error) {
-	var event eventline.Event
+// FetchEvents returns at most limit events of the current project from the
+// most recent to the oldest. Pagination stops at the first page containing
+// events older than since, a zero since and limit fetching all events.
+func (c *Client) FetchEvents(since time.Time, limit int) (Events, error) {
+	var events Events
+
+	cursor := eventline.Cursor{
+		Size:  eventline.MaxCursorSize,
+		Sort:  "event_time",
+		Order: eventline.OrderDesc,
+	}
+	if limit > 0 && limit < cursor.Size {
+		cursor.Size = limit
+	}
+
+	for {
+		var page EventPage
+
+		uri := NewURL("events")
+		uri.RawQuery = cursor.Query().Encode()
+
+		err := c.SendRequest("GET", uri, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page.Elements...)
+
+		if limit > 0 && len(events) >= limit {
+			events = events[:limit]
+			break
+		}
+
+		if page.Next == nil {
+			break
+		}
+
+		if n := len(page.Elements); n > 0 && !since.IsZero() &&
+			page.Elements[n-1].EventTime.Before(since) {
+			break
+		}
+
+		cursor = *page.Next
+	}
+
+	return events, nil
+}
+
+func (c *Client) FetchEventById(id eventline.Id) (*Event, error) {
+	uri := NewURL("events", "id", id.String())
+
+	var event Event
+
+	err := c.SendRequest("GET", uri, nil, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (c *Client) ReplayEvent(id string) (*Event, error) {
+	var event Event
 
 	uri := NewURL("events", "id", id, "replay")
 
@@ -347,12 +446,30 @@ func (c *Client) DeployJobs(specs []*eventline.JobSpec, dryRun bool) ([]*eventli
 	}
 }
 
+func (c *Client) RenameJob(id string, data *eventline.JobRenamingData) error {
+	uri := NewURL("jobs", "id", id, "rename")
+
+	return c.SendRequest("POST", uri, data, nil)
+}
+
 func (c *Client) DeleteJob(id string) error {
 	uri := NewURL("jobs", "id", id)
 
 	return c.SendRequest("DELETE", uri, nil, nil)
 }
 
+func (c *Client) EnableJob(id string) error {
+	uri := NewURL("jobs", "id", id, "enable")
+
+	return c.SendRequest("POST", uri, nil, nil)
+}
+
+func (c *Client) DisableJob(id string) error {
+	uri := NewURL("jobs", "id", id, "disable")
+
+	return c.SendRequest("POST", uri, nil, nil)
+}
+
 func (c *Client) ExecuteJob(id string, input *eventline.JobExecutionInput) (*eventline.JobExecution, error) {
 	uri := NewURL("jobs", "id", id, "execute")
 
@@ -0,0 +1,31 @@
+package evcli
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/exograd/eventline/pkg/eventline"
+)
+
+type EventPage struct {
+	Elements Events            `json:"elements"`
+	Previous *eventline.Cursor `json:"previous,omitempty"`
+	Next     *eventline.Cursor `json:"next,omitempty"`
+}
+
+// Event mirrors eventline.Event but keeps the event data raw: decoding it
+// requires the connector definitions which only live in the server.
+type Event struct {
+	Id              eventline.Id    `json:"id"`
+	ProjectId       eventline.Id    `json:"project_id"`
+	JobId           eventline.Id    `json:"job_id"`
+	CreationTime    time.Time       `json:"creation_time"`
+	EventTime       time.Time       `json:"event_time"`
+	Connector       string          `json:"connector"`
+	Name            string          `json:"name"`
+	RawData         json.RawMessage `json:"data"`
+	Processed       bool            `json:"processed,omitempty"`
+	OriginalEventId *eventline.Id   `json:"original_event_id,omitempty"`
+}
+
+type Events []*Event
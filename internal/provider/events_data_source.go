@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type EventsDataSource struct {
+	client *evcli.Client
+}
+
+var _ datasource.DataSource = &EventsDataSource{} // Ensure provider defined types fully satisfy framework interfaces
+func NewEventsDataSource() datasource.DataSource {
+	return &EventsDataSource{}
+}
+
+// defaultEventsLimit is the number of events fetched when no limit is set.
+const defaultEventsLimit = 100
+
+type EventsDataSourceModel struct {
+	After     types.String           `tfsdk:"after"`
+	Before    types.String           `tfsdk:"before"`
+	Connector types.String           `tfsdk:"connector"`
+	Elements  []EventDataSourceModel `tfsdk:"elements"`
+	Limit     types.Int64            `tfsdk:"limit"`
+	Name      types.String           `tfsdk:"name"`
+	ProjectId types.String           `tfsdk:"project_id"`
+}
+type EventDataSourceModel struct {
+	Connector       types.String `tfsdk:"connector"`
+	CreationTime    types.String `tfsdk:"creation_time"`
+	EventTime       types.String `tfsdk:"event_time"`
+	Id              types.String `tfsdk:"id"`
+	JobId           types.String `tfsdk:"job_id"`
+	Name            types.String `tfsdk:"name"`
+	OriginalEventId types.String `tfsdk:"original_event_id"`
+	Processed       types.Bool   `tfsdk:"processed"`
+	RawData         types.String `tfsdk:"data"`
+}
+
+func (d *EventsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_events"
+}
+
+func (d *EventsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"after": schema.StringAttribute{
+				MarkdownDescription: "Only return events which occurred at or after this RFC3339 timestamp.",
+				Optional:            true,
+			},
+			"before": schema.StringAttribute{
+				MarkdownDescription: "Only return events which occurred strictly before this RFC3339 timestamp.",
+				Optional:            true,
+			},
+			"connector": schema.StringAttribute{
+				MarkdownDescription: "Only return events emitted by this connector.",
+				Optional:            true,
+			},
+			"elements": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The list of events, from the most recent to the oldest.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"connector": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The connector which emitted the event.",
+						},
+						"creation_time": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The RFC3339 timestamp at which the event was created in eventline.",
+						},
+						"data": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The json raw data of the event.",
+						},
+						"event_time": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The RFC3339 timestamp at which the event occurred.",
+						},
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The identifier of the event.",
+						},
+						"job_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The identifier of the job triggered by the event.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the event.",
+						},
+						"original_event_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The identifier of the original event if this event is a replay.",
+						},
+						"processed": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the event has been processed or not.",
+						},
+					},
+				},
+			},
+			"limit": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The maximum number of events fetched from the most recent one, before they are filtered by `before`, `connector` and `name`. Events are fetched by pages of up to 100. Defaults to `%d`.", defaultEventsLimit),
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Only return events with this name.",
+				Optional:            true,
+			},
+			"project_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the project the events are part of.",
+				Required:            true,
+			},
+		},
+		MarkdownDescription: "Use this data source to retrieve information about recent eventline events. Only the most recent events are fetched, see `limit`.",
+	}
+}
+
+func (d *EventsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client, _ = req.ProviderData.(*evcli.Client)
+}
+
+func (d *EventsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addUnconfiguredProviderError(&resp.Diagnostics)
+		return
+	}
+	var data EventsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var id ksuid.KSUID
+	if err := id.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	var after, before time.Time
+	if !data.After.IsNull() {
+		var err error
+		if after, err = time.Parse(time.RFC3339, data.After.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("after"), "TimeParse", fmt.Sprintf("Unable to parse after timestamp, got error: %s", err))
+			return
+		}
+	}
+	if !data.Before.IsNull() {
+		var err error
+		if before, err = time.Parse(time.RFC3339, data.Before.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("before"), "TimeParse", fmt.Sprintf("Unable to parse before timestamp, got error: %s", err))
+			return
+		}
+	}
+	limit := int64(defaultEventsLimit)
+	if !data.Limit.IsNull() {
+		limit = data.Limit.ValueInt64()
+	}
+	d.client.ProjectId = &id
+	events, err := d.client.FetchEvents(after, int(limit))
+	if err != nil {
+		resp.Diagnostics.AddError("FetchEvents", fmt.Sprintf("Unable to fetch events, got error: %s", err))
+		return
+	}
+	eventList := make([]EventDataSourceModel, 0, len(events))
+	for _, event := range events {
+		if !data.Connector.IsNull() && event.Connector != data.Connector.ValueString() {
+			continue
+		}
+		if !data.Name.IsNull() && event.Name != data.Name.ValueString() {
+			continue
+		}
+		if !after.IsZero() && event.EventTime.Before(after) {
+			continue
+		}
+		if !before.IsZero() && !event.EventTime.Before(before) {
+			continue
+		}
+		element := EventDataSourceModel{
+			Connector:       types.StringValue(event.Connector),
+			CreationTime:    types.StringValue(event.CreationTime.Format(time.RFC3339)),
+			EventTime:       types.StringValue(event.EventTime.Format(time.RFC3339)),
+			Id:              types.StringValue(event.Id.String()),
+			JobId:           types.StringValue(event.JobId.String()),
+			Name:            types.StringValue(event.Name),
+			OriginalEventId: types.StringNull(),
+			Processed:       types.BoolValue(event.Processed),
+			RawData:         types.StringValue(string(event.RawData)),
+		}
+		if event.OriginalEventId != nil {
+			element.OriginalEventId = types.StringValue(event.OriginalEventId.String())
+		}
+		eventList = append(eventList, element)
+	}
+	data.Elements = eventList
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
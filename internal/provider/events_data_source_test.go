@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsDataSourceLimit(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeEventline(t)
+	projectId := ksuid.Generate().String()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 250; i++ {
+		fake.addEvent(projectId, &evcli.Event{
+			Connector: "generic",
+			EventTime: start.Add(time.Duration(i) * time.Minute),
+			Id:        ksuid.Generate(),
+			Name:      "tick",
+		})
+	}
+	var schemaResp datasource.SchemaResponse
+	NewEventsDataSource().Schema(context.Background(), datasource.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema}
+
+	testCases := []struct {
+		name     string
+		after    types.String
+		limit    types.Int64
+		events   int
+		requests int
+	}{
+		{"default limit", types.StringNull(), types.Int64Null(), 100, 1},
+		{"small limit", types.StringNull(), types.Int64Value(10), 10, 1},
+		{"limit over a page", types.StringNull(), types.Int64Value(150), 150, 2},
+		{"limit over the history", types.StringNull(), types.Int64Value(1000), 250, 3},
+		{"after", types.StringValue(start.Add(200 * time.Minute).Format(time.RFC3339)), types.Int64Value(1000), 50, 1},
+	}
+	for _, tc := range testCases {
+		resp, err := server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+			Config: testValue(t, state, &EventsDataSourceModel{
+				After:     tc.after,
+				Before:    types.StringNull(),
+				Connector: types.StringNull(),
+				Limit:     tc.limit,
+				Name:      types.StringNull(),
+				ProjectId: types.StringValue(projectId),
+			}),
+			TypeName: "eventline_events",
+		})
+		if !assert.NoError(err, tc.name) {
+			continue
+		}
+		checkDiagnostics(t, resp.Diagnostics)
+		var data EventsDataSourceModel
+		decodeTestValue(t, state, resp.State, &data)
+		if assert.Len(data.Elements, tc.events, tc.name) {
+			assert.Equal(start.Add(249*time.Minute).Format(time.RFC3339), data.Elements[0].EventTime.ValueString(), tc.name)
+		}
+		assert.Len(fake.takeRequests(), tc.requests, tc.name)
+	}
+}
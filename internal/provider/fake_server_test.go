@@ -0,0 +1,483 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// fakeEventline is an in memory implementation of the subset of the eventline
+// api used by the resources, for testing them through the provider protocol.
+type fakeEventline struct {
+	mu         sync.Mutex
+	events     map[string]evcli.Events // indexed by project id, most recent first
+	headers    http.Header             // of the last request
+	identities map[string]*evcli.Identity
+	jobs       map[string]eventline.Jobs // indexed by project id
+	projects   map[string]*eventline.Project
+	requests   []string
+}
+
+func newFakeEventline(t *testing.T) (*fakeEventline, tfprotov6.ProviderServer) {
+	return newFakeEventlineWithHeaders(t, types.MapNull(types.StringType))
+}
+
+// newFakeEventlineWithHeaders returns a fake eventline server and a provider
+// configured to send it additional headers.
+func newFakeEventlineWithHeaders(t *testing.T, headers types.Map) (*fakeEventline, tfprotov6.ProviderServer) {
+	f := &fakeEventline{
+		events:     make(map[string]evcli.Events),
+		identities: make(map[string]*evcli.Identity),
+		jobs:       make(map[string]eventline.Jobs),
+		projects:   make(map[string]*eventline.Project),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	p := New("test")()
+	var schemaResp provider.SchemaResponse
+	p.Schema(context.Background(), provider.SchemaRequest{}, &schemaResp)
+	config := testValue(t, tfsdk.State{Schema: schemaResp.Schema}, &ProviderModel{
+		ApiKey:                    types.StringValue("key"),
+		ApiKeyCommand:             types.ListNull(types.StringType),
+		ApiKeyFile:                types.StringNull(),
+		Endpoint:                  types.StringValue(server.URL),
+		Headers:                   headers,
+		SkipCredentialsValidation: types.BoolValue(true),
+	})
+	providerServer := providerserver.NewProtocol6(p)()
+	resp, err := providerServer.ConfigureProvider(context.Background(), &tfprotov6.ConfigureProviderRequest{
+		Config:           config,
+		TerraformVersion: "1.14.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, resp.Diagnostics)
+	return f, providerServer
+}
+
+func (f *fakeEventline) addJob(projectId string, spec *eventline.JobSpec) *eventline.Job {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var pid ksuid.KSUID
+	pid.Parse(projectId)
+	job := &eventline.Job{Id: ksuid.Generate(), ProjectId: pid, Spec: spec}
+	f.jobs[projectId] = append(f.jobs[projectId], job)
+	return job
+}
+
+func (f *fakeEventline) addEvent(projectId string, event *evcli.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[projectId] = append(evcli.Events{event}, f.events[projectId]...)
+}
+
+// takeRequests returns the requests received since the last call, formatted
+// as "<method> <path>".
+func (f *fakeEventline) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func (f *fakeEventline) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headers = r.Header.Clone()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	projectId := r.Header.Get("X-Eventline-Project-Id")
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := r.Method + " " + segments[0]
+	if len(segments) > 1 {
+		route += "/" + segments[1]
+	}
+	switch route {
+	case "GET events":
+		// Cursors are offsets in the list of events of the project.
+		events := f.events[projectId]
+		size, err := strconv.Atoi(r.URL.Query().Get("size"))
+		if err != nil || size < eventline.MinCursorSize || size > eventline.MaxCursorSize {
+			replyError(w, 400, "invalid_request_body", "invalid cursor size")
+			return
+		}
+		offset := 0
+		if after := r.URL.Query().Get("after"); after != "" {
+			key, err := base64.StdEncoding.DecodeString(after)
+			if err != nil {
+				replyError(w, 400, "invalid_request_body", "invalid cursor")
+				return
+			}
+			offset, _ = strconv.Atoi(string(key))
+		}
+		end := min(offset+size, len(events))
+		page := evcli.EventPage{Elements: events[offset:end]}
+		if end < len(events) {
+			page.Next = &eventline.Cursor{After: strconv.Itoa(end), Size: size}
+		}
+		reply(w, 200, page)
+	case "GET identities":
+		elements := evcli.Identities{}
+		for _, identity := range f.identities {
+			if identity.ProjectId.String() == projectId {
+				elements = append(elements, identity)
+			}
+		}
+		reply(w, 200, evcli.IdentityPage{Elements: elements})
+	case "POST identities":
+		var identity evcli.Identity
+		if err := json.NewDecoder(r.Body).Decode(&identity); err != nil {
+			replyError(w, 400, "invalid_request_body", err.Error())
+			return
+		}
+		for _, other := range f.identities {
+			if other.ProjectId.String() == projectId && other.Name == identity.Name {
+				replyError(w, 400, "duplicate_identity_name", fmt.Sprintf("duplicate identity name %q", identity.Name))
+				return
+			}
+		}
+		var pid ksuid.KSUID
+		if err := pid.Parse(projectId); err != nil {
+			replyError(w, 400, "invalid_project_id", err.Error())
+			return
+		}
+		identity.Id = ksuid.Generate()
+		identity.ProjectId = &pid
+		identity.Status = eventline.IdentityStatusReady
+		f.identities[identity.Id.String()] = &identity
+		reply(w, 201, identity)
+	case "GET identities/name":
+		for _, identity := range f.identities {
+			if identity.ProjectId.String() == projectId && identity.Name == segments[2] {
+				reply(w, 200, identity)
+				return
+			}
+		}
+		replyError(w, 404, "unknown_identity", fmt.Sprintf("unknown identity %q", segments[2]))
+	case "GET identities/id", "PUT identities/id", "DELETE identities/id":
+		identity, found := f.identities[segments[2]]
+		if !found || identity.ProjectId.String() != projectId {
+			replyError(w, 404, "unknown_identity", fmt.Sprintf("unknown identity %q", segments[2]))
+			return
+		}
+		switch r.Method {
+		case "GET":
+			reply(w, 200, identity)
+		case "PUT":
+			var update evcli.Identity
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				replyError(w, 400, "invalid_request_body", err.Error())
+				return
+			}
+			if update.Name != identity.Name {
+				for _, job := range f.jobs[projectId] {
+					if jobUsesIdentity(job.Spec, identity.Name) {
+						replyError(w, 400, "identity_in_use", fmt.Sprintf("identity %s is in use", identity.Id))
+						return
+					}
+				}
+			}
+			identity.Connector = update.Connector
+			identity.Name = update.Name
+			identity.RawData = update.RawData
+			identity.Type = update.Type
+			reply(w, 200, identity)
+		case "DELETE":
+			for _, job := range f.jobs[projectId] {
+				if jobUsesIdentity(job.Spec, identity.Name) {
+					replyError(w, 400, "identity_in_use", fmt.Sprintf("identity %s is in use", identity.Id))
+					return
+				}
+			}
+			delete(f.identities, segments[2])
+			w.WriteHeader(204)
+		}
+	case "GET jobs":
+		reply(w, 200, evcli.JobPage{Elements: append(eventline.Jobs{}, f.jobs[projectId]...)})
+	case "PUT jobs":
+		var specs []*eventline.JobSpec
+		if err := json.NewDecoder(r.Body).Decode(&specs); err != nil {
+			replyError(w, 400, "invalid_request_body", err.Error())
+			return
+		}
+		if r.URL.Query().Has("dry-run") {
+			w.WriteHeader(204)
+			return
+		}
+		jobs := eventline.Jobs{}
+		for _, spec := range specs {
+			job := f.findJobByName(projectId, spec.Name)
+			if job == nil {
+				var pid ksuid.KSUID
+				pid.Parse(projectId)
+				job = &eventline.Job{Id: ksuid.Generate(), ProjectId: pid}
+				f.jobs[projectId] = append(f.jobs[projectId], job)
+			}
+			job.Spec = spec
+			jobs = append(jobs, job)
+		}
+		reply(w, 200, jobs)
+	case "GET jobs/name":
+		if job := f.findJobByName(projectId, segments[2]); job != nil {
+			reply(w, 200, job)
+			return
+		}
+		replyError(w, 404, "unknown_job", fmt.Sprintf("unknown job %q", segments[2]))
+	case "DELETE jobs/id", "POST jobs/id":
+		for i, job := range f.jobs[projectId] {
+			if job.Id.String() != segments[2] {
+				continue
+			}
+			switch {
+			case r.Method == "DELETE" && len(segments) == 3:
+				f.jobs[projectId] = append(f.jobs[projectId][:i:i], f.jobs[projectId][i+1:]...)
+			case len(segments) == 4 && (segments[3] == "enable" || segments[3] == "disable"):
+				job.Disabled = segments[3] == "disable"
+			case len(segments) == 4 && segments[3] == "rename":
+				var data eventline.JobRenamingData
+				if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+					replyError(w, 400, "invalid_request_body", err.Error())
+					return
+				}
+				if other := f.findJobByName(projectId, data.Name); other != nil && other != job {
+					// Eventline fails on the unique constraint of job names
+					replyError(w, 500, "internal_error", fmt.Sprintf("duplicate job name %q", data.Name))
+					return
+				}
+				spec := *job.Spec
+				spec.Name = data.Name
+				spec.Description = data.Description
+				job.Spec = &spec
+			default:
+				replyError(w, 404, "route_not_found", fmt.Sprintf("unknown route %s", r.URL.Path))
+				return
+			}
+			w.WriteHeader(204)
+			return
+		}
+		replyError(w, 404, "unknown_job", fmt.Sprintf("unknown job %q", segments[2]))
+	case "GET projects":
+		elements := eventline.Projects{}
+		for _, project := range f.projects {
+			elements = append(elements, project)
+		}
+		reply(w, 200, evcli.ProjectPage{Elements: elements})
+	case "POST projects":
+		var project eventline.Project
+		if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+			replyError(w, 400, "invalid_request_body", err.Error())
+			return
+		}
+		for _, other := range f.projects {
+			if other.Name == project.Name {
+				replyError(w, 400, "duplicate_project_name", fmt.Sprintf("duplicate project name %q", project.Name))
+				return
+			}
+		}
+		project.Id = ksuid.Generate()
+		f.projects[project.Id.String()] = &project
+		reply(w, 201, project)
+	case "GET projects/name":
+		for _, project := range f.projects {
+			if project.Name == segments[2] {
+				reply(w, 200, project)
+				return
+			}
+		}
+		replyError(w, 404, "unknown_project", fmt.Sprintf("unknown project %q", segments[2]))
+	case "GET projects/id", "PUT projects/id", "DELETE projects/id":
+		project, found := f.projects[segments[2]]
+		if !found {
+			replyError(w, 404, "unknown_project", fmt.Sprintf("unknown project %q", segments[2]))
+			return
+		}
+		switch r.Method {
+		case "GET":
+			reply(w, 200, project)
+		case "PUT":
+			var update eventline.Project
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				replyError(w, 400, "invalid_request_body", err.Error())
+				return
+			}
+			project.Name = update.Name
+			reply(w, 200, project)
+		case "DELETE":
+			delete(f.projects, segments[2])
+			delete(f.jobs, segments[2])
+			for id, identity := range f.identities {
+				if identity.ProjectId.String() == segments[2] {
+					delete(f.identities, id)
+				}
+			}
+			w.WriteHeader(204)
+		}
+	default:
+		replyError(w, 404, "route_not_found", fmt.Sprintf("unknown route %s", r.URL.Path))
+	}
+}
+
+func (f *fakeEventline) findJobByName(projectId, name string) *eventline.Job {
+	for _, job := range f.jobs[projectId] {
+		if job.Spec.Name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+func reply(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func replyError(w http.ResponseWriter, status int, code, message string) {
+	reply(w, status, evcli.APIError{Code: code, Message: message})
+}
+
+// testValue encodes a model with the schema of an empty state for the provider
+// protocol, a nil model being encoded as a null value.
+func testValue(t *testing.T, state tfsdk.State, model interface{}) *tfprotov6.DynamicValue {
+	ctx := context.Background()
+	typ := state.Schema.Type().TerraformType(ctx)
+	state.Raw = tftypes.NewValue(typ, nil)
+	if model != nil {
+		if diags := state.Set(ctx, model); diags.HasError() {
+			t.Fatalf("cannot encode test value: %v", diags)
+		}
+	}
+	value, err := tfprotov6.NewDynamicValue(typ, state.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &value
+}
+
+// decodeTestValue decodes a value returned through the provider protocol into
+// a model.
+func decodeTestValue(t *testing.T, state tfsdk.State, value *tfprotov6.DynamicValue, model interface{}) {
+	ctx := context.Background()
+	raw, err := value.Unmarshal(state.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Raw = raw
+	if diags := state.Get(ctx, model); diags.HasError() {
+		t.Fatalf("cannot decode test value: %v", diags)
+	}
+}
+
+// testResource drives a resource through the provider protocol, keeping the
+// private state of the last applied change.
+type testResource struct {
+	private  []byte
+	server   tfprotov6.ProviderServer
+	state    tfsdk.State
+	typeName string
+}
+
+func newTestResource(server tfprotov6.ProviderServer, r resource.Resource) *testResource {
+	ctx := context.Background()
+	var metadataResp resource.MetadataResponse
+	r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "eventline"}, &metadataResp)
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	return &testResource{
+		server:   server,
+		state:    tfsdk.State{Schema: schemaResp.Schema},
+		typeName: metadataResp.TypeName,
+	}
+}
+
+// plan plans a change from the prior model to the proposed one, nil models
+// standing for a resource being created or destroyed.
+func (r *testResource) plan(t *testing.T, prior, config, proposed interface{}) *tfprotov6.PlanResourceChangeResponse {
+	resp, err := r.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		Config:           testValue(t, r.state, config),
+		PriorPrivate:     r.private,
+		PriorState:       testValue(t, r.state, prior),
+		ProposedNewState: testValue(t, r.state, proposed),
+		TypeName:         r.typeName,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// apply plans then applies a change, decoding the new state in newState when
+// one is returned and returning the diagnostics of the plan if it failed or of
+// the apply.
+func (r *testResource) apply(t *testing.T, prior, config, proposed, newState interface{}) []*tfprotov6.Diagnostic {
+	plan := r.plan(t, prior, config, proposed)
+	if hasErrorDiagnostic(plan.Diagnostics) {
+		return plan.Diagnostics
+	}
+	resp, err := r.server.ApplyResourceChange(context.Background(), &tfprotov6.ApplyResourceChangeRequest{
+		Config:         testValue(t, r.state, config),
+		PlannedPrivate: plan.PlannedPrivate,
+		PlannedState:   plan.PlannedState,
+		PriorState:     testValue(t, r.state, prior),
+		TypeName:       r.typeName,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.private = resp.Private
+	if newState != nil && proposed != nil && resp.NewState != nil {
+		if null, err := resp.NewState.IsNull(); err == nil && !null {
+			decodeTestValue(t, r.state, resp.NewState, newState)
+		}
+	}
+	return resp.Diagnostics
+}
+
+func hasErrorDiagnostic(diags []*tfprotov6.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnosticSummaries returns the summaries of the diagnostics of a severity.
+func diagnosticSummaries(diags []*tfprotov6.Diagnostic, severity tfprotov6.DiagnosticSeverity) []string {
+	var summaries []string
+	for _, d := range diags {
+		if d.Severity == severity {
+			summaries = append(summaries, d.Summary)
+		}
+	}
+	return summaries
+}
+
+func checkDiagnostics(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+}
@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
 	"github.com/exograd/eventline/pkg/ksuid"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -21,8 +23,13 @@ func NewIdentitiesDataSource() datasource.DataSource {
 }
 
 type IdentitiesDataSourceModel struct {
-	Elements  []IdentityDataSourceModel `tfsdk:"elements"`
-	ProjectId types.String              `tfsdk:"project_id"`
+	Connector   types.String              `tfsdk:"connector"`
+	Elements    []IdentityDataSourceModel `tfsdk:"elements"`
+	IncludeData types.Bool                `tfsdk:"include_data"`
+	NameRegex   types.String              `tfsdk:"name_regex"`
+	ProjectId   types.String              `tfsdk:"project_id"`
+	Status      types.String              `tfsdk:"status"`
+	Type        types.String              `tfsdk:"type"`
 }
 type IdentityDataSourceModel struct {
 	Connector types.String `tfsdk:"connector"`
@@ -40,6 +47,10 @@ func (d *IdentitiesDataSource) Metadata(ctx context.Context, req datasource.Meta
 func (d *IdentitiesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"connector": schema.StringAttribute{
+				MarkdownDescription: "Only return identities of this connector.",
+				Optional:            true,
+			},
 			"elements": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
@@ -50,7 +61,7 @@ func (d *IdentitiesDataSource) Schema(ctx context.Context, req datasource.Schema
 						},
 						"data": schema.StringAttribute{
 							Computed:            true,
-							MarkdownDescription: "The json raw data of the identity.",
+							MarkdownDescription: "The json raw data of the identity, only set when `include_data` is true.",
 							Sensitive:           true,
 						},
 						"id": schema.StringAttribute{
@@ -73,10 +84,26 @@ func (d *IdentitiesDataSource) Schema(ctx context.Context, req datasource.Schema
 				},
 				MarkdownDescription: "Identities list",
 			},
+			"include_data": schema.BoolAttribute{
+				MarkdownDescription: "Whether to fetch the secret data of the identities into the state or not. Defaults to false.",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only return identities whose name matches this regular expression.",
+				Optional:            true,
+			},
 			"project_id": schema.StringAttribute{
 				MarkdownDescription: "Project id",
 				Required:            true,
 			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Only return identities with this status, either `pending`, `ready` or `error`.",
+				Optional:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Only return identities of this type.",
+				Optional:            true,
+			},
 		},
 		MarkdownDescription: "Eventline identities data source",
 	}
@@ -87,6 +114,10 @@ func (d *IdentitiesDataSource) Configure(ctx context.Context, req datasource.Con
 }
 
 func (d *IdentitiesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addUnconfiguredProviderError(&resp.Diagnostics)
+		return
+	}
 	var data IdentitiesDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
@@ -97,22 +128,46 @@ func (d *IdentitiesDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
 		return
 	}
+	var nameRegex *regexp.Regexp
+	if !data.NameRegex.IsNull() {
+		var err error
+		if nameRegex, err = regexp.Compile(data.NameRegex.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "RegexpCompile", fmt.Sprintf("Unable to compile name regular expression, got error: %s", err))
+			return
+		}
+	}
 	d.client.ProjectId = &id
 	identities, err := d.client.FetchIdentities()
 	if err != nil {
 		resp.Diagnostics.AddError("FetchIdentities", fmt.Sprintf("Unable to fetch identities, got error: %s", err))
 		return
 	}
-	identityList := make([]IdentityDataSourceModel, len(identities))
-	for i, identity := range identities {
-		identityList[i] = IdentityDataSourceModel{
+	identityList := make([]IdentityDataSourceModel, 0, len(identities))
+	for _, identity := range identities {
+		if !data.Connector.IsNull() && identity.Connector != data.Connector.ValueString() {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(identity.Name) {
+			continue
+		}
+		if !data.Status.IsNull() && string(identity.Status) != data.Status.ValueString() {
+			continue
+		}
+		if !data.Type.IsNull() && identity.Type != data.Type.ValueString() {
+			continue
+		}
+		element := IdentityDataSourceModel{
 			Connector: types.StringValue(identity.Connector),
 			Id:        types.StringValue(identity.Id.String()),
 			Name:      types.StringValue(identity.Name),
-			RawData:   types.StringValue(string(identity.RawData)),
+			RawData:   types.StringNull(),
 			Status:    types.StringValue(string(identity.Status)),
 			Type:      types.StringValue(identity.Type),
 		}
+		if data.IncludeData.ValueBool() {
+			element.RawData = types.StringValue(string(identity.RawData))
+		}
+		identityList = append(identityList, element)
 	}
 	data.Elements = identityList
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
 	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -21,20 +25,71 @@ type IdentityResource struct {
 	client *evcli.Client
 }
 
-var _ resource.Resource = &IdentityResource{}                // Ensure provider defined types fully satisfy framework interfaces
-var _ resource.ResourceWithImportState = &IdentityResource{} // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &IdentityResource{}                     // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithConfigValidators = &IdentityResource{} // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithImportState = &IdentityResource{}      // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithModifyPlan = &IdentityResource{}       // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithValidateConfig = &IdentityResource{}   // Ensure provider defined types fully satisfy framework interfaces
 func NewIdentityResource() resource.Resource {
 	return &IdentityResource{}
 }
 
 type IdentityResourceModel struct {
-	Connector types.String `tfsdk:"connector"`
-	Id        types.String `tfsdk:"id"`
-	Name      types.String `tfsdk:"name"`
-	ProjectId types.String `tfsdk:"project_id"`
-	RawData   types.String `tfsdk:"data"`
-	Status    types.String `tfsdk:"status"`
-	Type      types.String `tfsdk:"type"`
+	AdoptExisting      types.Bool   `tfsdk:"adopt_existing"`
+	Connector          types.String `tfsdk:"connector"`
+	DockerhubPassword  types.Object `tfsdk:"dockerhub_password"`
+	DockerhubToken     types.Object `tfsdk:"dockerhub_token"`
+	EventlineApiKey    types.Object `tfsdk:"eventline_api_key"`
+	GenericApiKey      types.Object `tfsdk:"generic_api_key"`
+	GenericGpgKey      types.Object `tfsdk:"generic_gpg_key"`
+	GenericPassword    types.Object `tfsdk:"generic_password"`
+	GenericSshKey      types.Object `tfsdk:"generic_ssh_key"`
+	GithubToken        types.Object `tfsdk:"github_token"`
+	Id                 types.String `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
+	PostgresqlPassword types.Object `tfsdk:"postgresql_password"`
+	ProjectId          types.String `tfsdk:"project_id"`
+	RawData            JSONValue    `tfsdk:"data"`
+	Status             types.String `tfsdk:"status"`
+	Type               types.String `tfsdk:"type"`
+}
+
+// typedData returns the typed identity attributes indexed by name.
+func (m *IdentityResourceModel) typedData() map[string]*types.Object {
+	return map[string]*types.Object{
+		"dockerhub_password":  &m.DockerhubPassword,
+		"dockerhub_token":     &m.DockerhubToken,
+		"eventline_api_key":   &m.EventlineApiKey,
+		"generic_api_key":     &m.GenericApiKey,
+		"generic_gpg_key":     &m.GenericGpgKey,
+		"generic_password":    &m.GenericPassword,
+		"generic_ssh_key":     &m.GenericSshKey,
+		"github_token":        &m.GithubToken,
+		"postgresql_password": &m.PostgresqlPassword,
+	}
+}
+
+// typedIdentity returns the identity type and value of the typed attribute
+// set in the model, or nil if the raw data is used.
+func (m *IdentityResourceModel) typedIdentity() (*IdentityType, *types.Object) {
+	for name, obj := range m.typedData() {
+		if !obj.IsNull() {
+			return findIdentityTypeByAttributeName(name), obj
+		}
+	}
+	return nil, nil
+}
+
+// resolveRawData sets the raw data from the typed attribute if one is used
+// and returns it.
+func (m *IdentityResourceModel) resolveRawData(ctx context.Context) (json.RawMessage, diag.Diagnostics) {
+	identityType, obj := m.typedIdentity()
+	if identityType == nil {
+		return json.RawMessage(m.RawData.ValueString()), nil
+	}
+	data, diags := identityType.EncodeObject(ctx, *obj)
+	m.RawData = NewJSONValue(string(data))
+	return data, diags
 }
 
 func (r *IdentityResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -44,13 +99,21 @@ func (r *IdentityResource) Metadata(ctx context.Context, req resource.MetadataRe
 func (r *IdentityResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"adopt_existing": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				MarkdownDescription: "Whether to take ownership of an existing identity with the same name in the project instead of failing when creating the identity. The existing identity is then updated to match the configuration. Defaults to `false`.",
+				Optional:            true,
+			},
 			"connector": schema.StringAttribute{
-				MarkdownDescription: "The connector used for the identity.",
+				MarkdownDescription: "The connector used for the identity. Supported connectors are listed by the `eventline_identity_types` data source.",
 				Required:            true,
 			},
 			"data": schema.StringAttribute{
-				MarkdownDescription: "The json raw data of the identity.",
-				Required:            true,
+				Computed:            true,
+				CustomType:          JSONType{},
+				MarkdownDescription: "The json raw data of the identity. It is validated at plan time against the schema of the identity type and compared semantically, ignoring formatting, key order and the default values eventline fills in. Exactly one of `data` or a typed attribute such as `generic_api_key` must be set, this attribute being computed from the latter.",
+				Optional:            true,
 				Sensitive:           true,
 			},
 			"id": schema.StringAttribute{
@@ -61,30 +124,160 @@ func (r *IdentityResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"name": schema.StringAttribute{
-				MarkdownDescription: "The name of the identity.",
+				MarkdownDescription: "The name of the identity. Eventline refuses to rename an identity while jobs use it.",
 				Required:            true,
 			},
 			"project_id": schema.StringAttribute{
-				MarkdownDescription: "Project id",
-				Required:            true,
+				MarkdownDescription: "Project id. Changing it forces the identity to be replaced, which eventline only allows when no job uses it.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
 			},
 			"status": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "The status of the identity.",
 			},
 			"type": schema.StringAttribute{
-				MarkdownDescription: "The type of the identity.",
+				MarkdownDescription: "The type of the identity, which must be supported by the connector. The `oauth2` types are refused since eventline only creates such identities from its web interface.",
 				Required:            true,
 			},
 		},
 		MarkdownDescription: "Eventline identity resource",
 	}
+	for _, name := range identityTypeAttributeNames() {
+		resp.Schema.Attributes[name] = findIdentityTypeByAttributeName(name).schemaAttribute()
+	}
+}
+
+func (r *IdentityResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	expressions := []path.Expression{path.MatchRoot("data")}
+	for _, name := range identityTypeAttributeNames() {
+		expressions = append(expressions, path.MatchRoot(name))
+	}
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(expressions...),
+	}
 }
 
 func (r *IdentityResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	r.client, _ = req.ProviderData.(*evcli.Client)
 }
 
+func (r *IdentityResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data IdentityResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Connector.IsUnknown() || data.Connector.IsNull() {
+		return
+	}
+	connector := data.Connector.ValueString()
+	typeNames := connectorIdentityTypes(connector)
+	if typeNames == nil {
+		resp.Diagnostics.AddAttributeError(path.Root("connector"), "UnknownConnector", fmt.Sprintf("Unknown connector %q, expected one of: %s", connector, strings.Join(identityConnectors(), ", ")))
+		return
+	}
+	if data.Type.IsUnknown() || data.Type.IsNull() {
+		return
+	}
+	identityType := findIdentityType(connector, data.Type.ValueString())
+	if identityType == nil {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "UnknownIdentityType", fmt.Sprintf("Unknown identity type %q for connector %q, expected one of: %s", data.Type.ValueString(), connector, strings.Join(typeNames, ", ")))
+		return
+	}
+	if identityType.OAuth2 {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "UnsupportedIdentityType", fmt.Sprintf("Eventline refuses to create or update %s/%s identities through its api, they can only be created from the web interface", connector, identityType.Type))
+		return
+	}
+	for name, obj := range data.typedData() {
+		if !obj.IsNull() && name != identityType.AttributeName() {
+			resp.Diagnostics.AddAttributeError(path.Root(name), "IdentityTypeMismatch", fmt.Sprintf("Attribute %s cannot be used for %s/%s identities, use %s instead", name, connector, identityType.Type, identityType.AttributeName()))
+		}
+	}
+	if data.RawData.IsUnknown() || data.RawData.IsNull() {
+		return
+	}
+	for _, err := range identityType.ValidateData(data.RawData.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("data"), "InvalidIdentityData", fmt.Sprintf("Invalid %s/%s identity data: %s", connector, identityType.Type, err))
+	}
+}
+
+func (r *IdentityResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		var state IdentityResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() || r.client == nil {
+			return
+		}
+		var pid ksuid.KSUID
+		if err := pid.Parse(state.ProjectId.ValueString()); err != nil {
+			resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+			return
+		}
+		jobNames, err := r.dependentJobs(pid, state.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs, got error: %s", err))
+			return
+		}
+		if len(jobNames) > 0 {
+			resp.Diagnostics.AddWarning("IdentityInUse", fmt.Sprintf("Identity %s is used by jobs %s. Eventline will refuse to delete it unless these jobs stop using it or are destroyed first.", state.Name.ValueString(), strings.Join(jobNames, ", ")))
+		}
+		return
+	}
+	var data IdentityResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		var state IdentityResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !data.ProjectId.Equal(state.ProjectId) && r.client != nil {
+			var pid ksuid.KSUID
+			if err := pid.Parse(state.ProjectId.ValueString()); err != nil {
+				resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+				return
+			}
+			jobNames, err := r.dependentJobs(pid, state.Name.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs, got error: %s", err))
+				return
+			}
+			if len(jobNames) > 0 {
+				resp.Diagnostics.AddAttributeWarning(path.Root("project_id"), "IdentityInUse", fmt.Sprintf("Identity %s is used by jobs %s. Eventline cannot move an identity to another project, it must be deleted and created again which eventline will refuse unless these jobs stop using it or are destroyed first.", state.Name.ValueString(), strings.Join(jobNames, ", ")))
+			} else {
+				resp.Diagnostics.AddWarning("IdentityReplacement", fmt.Sprintf("Identity %s will be deleted and created again with a new id because eventline cannot move an identity to another project.", state.Name.ValueString()))
+			}
+		} else if !data.Name.Equal(state.Name) && r.client != nil {
+			var pid ksuid.KSUID
+			if err := pid.Parse(state.ProjectId.ValueString()); err != nil {
+				resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+				return
+			}
+			jobNames, err := r.dependentJobs(pid, state.Name.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs, got error: %s", err))
+				return
+			}
+			if len(jobNames) > 0 {
+				resp.Diagnostics.AddAttributeWarning(path.Root("name"), "IdentityInUse", fmt.Sprintf("Identity %s is used by jobs %s. Eventline will refuse to rename it unless these jobs stop using it first.", state.Name.ValueString(), strings.Join(jobNames, ", ")))
+			}
+		}
+	}
+	identityType, obj := data.typedIdentity()
+	if identityType == nil || !isFullyKnown(*obj) {
+		return
+	}
+	rawData, diags := identityType.EncodeObject(ctx, *obj)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("data"), NewJSONValue(string(rawData)))...)
+}
+
 func (r *IdentityResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data *IdentityResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -97,16 +290,34 @@ func (r *IdentityResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 	r.client.ProjectId = &id
+	rawData, diags := data.resolveRawData(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	identity := evcli.Identity{
 		Connector: data.Connector.ValueString(),
 		Name:      data.Name.ValueString(),
 		ProjectId: &id,
-		RawData:   json.RawMessage(data.RawData.ValueString()),
+		RawData:   rawData,
 		Type:      data.Type.ValueString(),
 	}
 	if err := r.client.CreateIdentity(&identity); err != nil {
-		resp.Diagnostics.AddError("CreateIdentity", fmt.Sprintf("Unable to create identity, got error: %s\nTry importing the resource instead?", err))
-		return
+		var e *evcli.APIError
+		if !data.AdoptExisting.ValueBool() || !errors.As(err, &e) || e.Code != "duplicate_identity_name" {
+			resp.Diagnostics.AddError("CreateIdentity", fmt.Sprintf("Unable to create identity, got error: %s\nTry importing the resource or setting adopt_existing instead?", err))
+			return
+		}
+		existing, err := r.client.FetchIdentityByName(identity.Name)
+		if err != nil {
+			resp.Diagnostics.AddError("FetchIdentityByName", fmt.Sprintf("Unable to fetch existing identity by name, got error: %s", err))
+			return
+		}
+		identity.Id = existing.Id
+		if err := r.client.UpdateIdentity(&identity); err != nil {
+			resp.Diagnostics.AddError("UpdateIdentity", fmt.Sprintf("Unable to update existing identity, got error: %s", err))
+			return
+		}
 	}
 	data.Id = types.StringValue(identity.Id.String())
 	data.Status = types.StringValue(string(identity.Status))
@@ -114,6 +325,9 @@ func (r *IdentityResource) Create(ctx context.Context, req resource.CreateReques
 }
 
 func (r *IdentityResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return // The provider configuration is not known yet, the state is kept as is
+	}
 	var data *IdentityResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
@@ -140,16 +354,33 @@ func (r *IdentityResource) Read(ctx context.Context, req resource.ReadRequest, r
 		resp.Diagnostics.AddError("FetchIdentityById", fmt.Sprintf("Unable to fetch identity by id, got error: %s", err))
 		return
 	}
+	if data.AdoptExisting.IsNull() {
+		data.AdoptExisting = types.BoolValue(false) // The identity was imported
+	}
 	data.Connector = types.StringValue(identity.Connector)
 	data.Id = types.StringValue(identity.Id.String())
 	data.Name = types.StringValue(identity.Name)
-	rawDataEquals, err := JSONRawDataEqual(identity.RawData, json.RawMessage(data.RawData.ValueString()))
-	if err != nil {
-		resp.Diagnostics.AddError("JSONRawDataequal", fmt.Sprintf("Unable to compare identities RawData, got error: %s", err))
-		return
-	}
-	if !rawDataEquals {
-		data.RawData = types.StringValue(string(identity.RawData))
+	if identityType, obj := data.typedIdentity(); identityType != nil && identityType.Connector == identity.Connector && identityType.Type == identity.Type {
+		var diags diag.Diagnostics
+		*obj, diags = identityType.DecodeObject(ctx, identity.RawData, *obj)
+		resp.Diagnostics.Append(diags...)
+		_, diags = data.resolveRawData(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else {
+		if identityType != nil {
+			*obj = types.ObjectNull(identityType.attributeTypes()) // The identity type changed outside of terraform
+		}
+		rawData := identity.RawData
+		if identityType := findIdentityType(identity.Connector, identity.Type); identityType != nil {
+			if rawData, err = identityType.NormalizeData(identity.RawData, json.RawMessage(data.RawData.ValueString())); err != nil {
+				resp.Diagnostics.AddError("NormalizeData", fmt.Sprintf("Unable to normalize identity data, got error: %s", err))
+				return
+			}
+		}
+		data.RawData = NewJSONValue(string(rawData)) // semantic equality keeps the prior value when both documents match
 	}
 	data.Status = types.StringValue(string(identity.Status))
 	data.Type = types.StringValue(identity.Type)
@@ -173,12 +404,17 @@ func (r *IdentityResource) Update(ctx context.Context, req resource.UpdateReques
 		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse identity id, got error: %s %s", err, data.Id.ValueString()))
 		return
 	}
+	rawData, diags := data.resolveRawData(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	identity := evcli.Identity{
 		Id:        id,
 		Name:      data.Name.ValueString(),
 		Connector: data.Connector.ValueString(),
 		ProjectId: &pid,
-		RawData:   json.RawMessage(data.RawData.ValueString()),
+		RawData:   rawData,
 		Type:      data.Type.ValueString(),
 	}
 	if err := r.client.UpdateIdentity(&identity); err != nil {
@@ -206,6 +442,15 @@ func (r *IdentityResource) Delete(ctx context.Context, req resource.DeleteReques
 		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse identity id, got error: %s", err))
 		return
 	}
+	jobNames, err := r.dependentJobs(pid, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs, got error: %s", err))
+		return
+	}
+	if len(jobNames) > 0 {
+		resp.Diagnostics.AddError("IdentityInUse", fmt.Sprintf("Unable to delete identity %s which is still used by jobs %s, update or destroy them first", data.Name.ValueString(), strings.Join(jobNames, ", ")))
+		return
+	}
 	if err := r.client.DeleteIdentity(id); err != nil {
 		var e *evcli.APIError
 		if errors.As(err, &e) && e.Code == "unknown_identity" {
@@ -216,6 +461,28 @@ func (r *IdentityResource) Delete(ctx context.Context, req resource.DeleteReques
 	}
 }
 
+// dependentJobs returns the sorted names of the jobs of a project using an
+// identity, be it in their identities, runner or trigger.
+func (r *IdentityResource) dependentJobs(projectId ksuid.KSUID, name string) ([]string, error) {
+	r.client.ProjectId = &projectId
+	jobs, err := r.client.FetchJobs()
+	if err != nil {
+		var e *evcli.APIError
+		if errors.As(err, &e) && e.Code == "unknown_project" {
+			return nil, nil // the identity was deleted along with its project
+		}
+		return nil, err
+	}
+	var jobNames []string
+	for _, job := range jobs {
+		if jobUsesIdentity(job.Spec, name) {
+			jobNames = append(jobNames, job.Spec.Name)
+		}
+	}
+	sort.Strings(jobNames)
+	return jobNames, nil
+}
+
 func (r *IdentityResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	idParts := strings.Split(req.ID, "/")
 	if len(idParts) != 2 || idParts[0] == "" || idParts[1] == "" {
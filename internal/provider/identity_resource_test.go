@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdentityTestModel(projectId, name, connector, identityType, data string) *IdentityResourceModel {
+	model := &IdentityResourceModel{
+		AdoptExisting: types.BoolNull(),
+		Connector:     types.StringValue(connector),
+		Id:            types.StringNull(),
+		Name:          types.StringValue(name),
+		ProjectId:     types.StringValue(projectId),
+		RawData:       NewJSONValue(data),
+		Status:        types.StringNull(),
+		Type:          types.StringValue(identityType),
+	}
+	for attributeName, obj := range model.typedData() {
+		*obj = types.ObjectNull(findIdentityTypeByAttributeName(attributeName).attributeTypes())
+	}
+	return model
+}
+
+// identityChange returns the prior, config and proposed values of a change
+// of identity, computed attributes being kept from the prior model.
+func identityChange(prior, config *IdentityResourceModel) (interface{}, interface{}, interface{}) {
+	if prior == nil {
+		return nil, config, config
+	}
+	proposed := *config
+	if proposed.AdoptExisting.IsNull() {
+		proposed.AdoptExisting = prior.AdoptExisting
+	}
+	proposed.Id = prior.Id
+	proposed.Status = prior.Status
+	return prior, config, &proposed
+}
+
+func TestIdentityResourceReplacement(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewIdentityResource())
+
+	projectId := ksuid.Generate().String()
+	var identity *IdentityResourceModel
+	prior, config, proposed := identityChange(nil, newIdentityTestModel(projectId, "test", "generic", "api_key", `{"key": "secret"}`))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &identity))
+	assert.Equal([]string{"POST /identities"}, f.takeRequests())
+	assert.False(identity.Id.IsNull())
+	assert.Equal("ready", identity.Status.ValueString())
+
+	otherProjectId := ksuid.Generate().String()
+	testCases := []struct {
+		name    string
+		config  *IdentityResourceModel
+		replace bool
+	}{
+		{"data", newIdentityTestModel(projectId, "test", "generic", "api_key", `{"key": "other"}`), false},
+		{"connector", newIdentityTestModel(projectId, "test", "eventline", "api_key", `{"key": "secret"}`), false},
+		{"type", newIdentityTestModel(projectId, "test", "generic", "password", `{"password": "secret"}`), false},
+		{"name", newIdentityTestModel(projectId, "renamed", "generic", "api_key", `{"key": "secret"}`), false},
+		{"project_id", newIdentityTestModel(otherProjectId, "renamed", "generic", "api_key", `{"key": "secret"}`), true},
+	}
+	for _, tc := range testCases {
+		prior, config, proposed := identityChange(identity, tc.config)
+		plan := r.plan(t, prior, config, proposed)
+		checkDiagnostics(t, plan.Diagnostics)
+		warnings := diagnosticSummaries(plan.Diagnostics, tfprotov6.DiagnosticSeverityWarning)
+		if !tc.replace {
+			assert.Empty(plan.RequiresReplace, tc.name)
+			assert.Empty(warnings, tc.name)
+			continue
+		}
+		assert.Equal([]*tftypes.AttributePath{tftypes.NewAttributePath().WithAttributeName("project_id")}, plan.RequiresReplace, tc.name)
+		assert.Equal([]string{"IdentityReplacement"}, warnings, tc.name)
+	}
+	f.takeRequests()
+
+	for _, tc := range testCases[:4] {
+		var updated *IdentityResourceModel
+		prior, config, proposed = identityChange(identity, tc.config)
+		checkDiagnostics(t, r.apply(t, prior, config, proposed, &updated))
+		requests := []string{"PUT /identities/id/" + identity.Id.ValueString()}
+		if tc.name == "name" {
+			requests = append([]string{"GET /jobs"}, requests...) // renames check the jobs using the identity
+		}
+		assert.Equal(requests, f.takeRequests(), tc.name)
+		assert.Equal(identity.Id, updated.Id, tc.name)
+		assert.Equal(tc.config.Connector.ValueString(), f.identities[identity.Id.ValueString()].Connector, tc.name)
+		assert.Equal(tc.config.Type.ValueString(), f.identities[identity.Id.ValueString()].Type, tc.name)
+		identity = updated
+	}
+	assert.Equal("renamed", identity.Name.ValueString())
+
+	// Terraform applies a replacement as a destroy followed by a create, jobs
+	// being checked when planning then when deleting
+	checkDiagnostics(t, r.apply(t, identity, nil, nil, nil))
+	var replaced *IdentityResourceModel
+	prior, config, proposed = identityChange(nil, testCases[4].config)
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &replaced))
+	assert.Equal([]string{"GET /jobs", "GET /jobs", "DELETE /identities/id/" + identity.Id.ValueString(), "POST /identities"}, f.takeRequests())
+	assert.NotEqual(identity.Id, replaced.Id)
+	assert.Equal(otherProjectId, f.identities[replaced.Id.ValueString()].ProjectId.String())
+	assert.Len(f.identities, 1)
+
+	// Replacing an identity used by jobs is only refused when destroying it,
+	// since jobs may stop using it earlier in the same apply
+	f.addJob(otherProjectId, &eventline.JobSpec{Identities: []string{"renamed"}, Name: "runner"})
+	prior, config, proposed = identityChange(replaced, newIdentityTestModel(projectId, "renamed", "generic", "api_key", `{"key": "secret"}`))
+	plan := r.plan(t, prior, config, proposed)
+	checkDiagnostics(t, plan.Diagnostics)
+	assert.Equal([]string{"IdentityInUse"}, diagnosticSummaries(plan.Diagnostics, tfprotov6.DiagnosticSeverityWarning))
+	assert.Contains(plan.Diagnostics[0].Detail, "jobs runner")
+	diags := r.apply(t, replaced, nil, nil, nil)
+	assert.Equal([]string{"IdentityInUse"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	assert.Len(f.identities, 1)
+}
+
+func TestIdentityResourceDeletion(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewIdentityResource())
+
+	projectId := ksuid.Generate().String()
+	var identity *IdentityResourceModel
+	prior, config, proposed := identityChange(nil, newIdentityTestModel(projectId, "test", "generic", "api_key", `{"key": "secret"}`))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &identity))
+	f.addJob(projectId, &eventline.JobSpec{Name: "runner", Runner: &eventline.JobRunner{Name: "ssh", Identity: "test"}})
+	f.addJob(projectId, &eventline.JobSpec{Name: "other"})
+	f.addJob(projectId, &eventline.JobSpec{Identities: []string{"test"}, Name: "identities"})
+	f.takeRequests()
+
+	plan := r.plan(t, identity, nil, nil)
+	checkDiagnostics(t, plan.Diagnostics)
+	assert.Equal([]string{"IdentityInUse"}, diagnosticSummaries(plan.Diagnostics, tfprotov6.DiagnosticSeverityWarning))
+	assert.Contains(plan.Diagnostics[0].Detail, "identities, runner")
+	f.takeRequests()
+
+	// Jobs are checked when planning then when deleting
+	diags := r.apply(t, identity, nil, nil, nil)
+	assert.Equal([]string{"IdentityInUse"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	assert.Equal([]string{"GET /jobs", "GET /jobs"}, f.takeRequests())
+	assert.Len(f.identities, 1)
+
+	delete(f.jobs, projectId)
+	plan = r.plan(t, identity, nil, nil)
+	assert.Empty(plan.Diagnostics)
+	checkDiagnostics(t, r.apply(t, identity, nil, nil, nil))
+	assert.Empty(f.identities)
+}
+
+func TestIdentityResourceRename(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewIdentityResource())
+
+	projectId := ksuid.Generate().String()
+	var identity *IdentityResourceModel
+	prior, config, proposed := identityChange(nil, newIdentityTestModel(projectId, "test", "generic", "api_key", `{"key": "secret"}`))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &identity))
+	f.addJob(projectId, &eventline.JobSpec{Name: "runner", Runner: &eventline.JobRunner{Name: "ssh", Identity: "test"}})
+	f.takeRequests()
+
+	prior, config, proposed = identityChange(identity, newIdentityTestModel(projectId, "renamed", "generic", "api_key", `{"key": "secret"}`))
+	plan := r.plan(t, prior, config, proposed)
+	checkDiagnostics(t, plan.Diagnostics)
+	assert.Equal([]string{"IdentityInUse"}, diagnosticSummaries(plan.Diagnostics, tfprotov6.DiagnosticSeverityWarning))
+	assert.Contains(plan.Diagnostics[0].Detail, "jobs runner")
+	f.takeRequests()
+
+	diags := r.apply(t, prior, config, proposed, nil)
+	assert.Equal([]string{"UpdateIdentity"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	assert.Equal("test", f.identities[identity.Id.ValueString()].Name)
+
+	// Other changes are still applied in place
+	prior, config, proposed = identityChange(identity, newIdentityTestModel(projectId, "test", "generic", "api_key", `{"key": "other"}`))
+	plan = r.plan(t, prior, config, proposed)
+	assert.Empty(plan.Diagnostics)
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, nil))
+	assert.JSONEq(`{"key": "other"}`, string(f.identities[identity.Id.ValueString()].RawData))
+}
+
+func TestIdentityResourceAdoptExisting(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewIdentityResource())
+
+	projectId := ksuid.Generate().String()
+	var existing *IdentityResourceModel
+	prior, config, proposed := identityChange(nil, newIdentityTestModel(projectId, "test", "generic", "api_key", `{"key": "secret"}`))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &existing))
+	f.takeRequests()
+
+	model := newIdentityTestModel(projectId, "test", "generic", "password", `{"password": "secret"}`)
+	prior, config, proposed = identityChange(nil, model)
+	diags := r.apply(t, prior, config, proposed, nil)
+	assert.Equal([]string{"CreateIdentity"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	f.takeRequests()
+
+	model.AdoptExisting = types.BoolValue(true)
+	var identity *IdentityResourceModel
+	prior, config, proposed = identityChange(nil, model)
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &identity))
+	assert.Equal([]string{"POST /identities", "GET /identities/name/test", "PUT /identities/id/" + existing.Id.ValueString()}, f.takeRequests())
+	assert.Equal(existing.Id, identity.Id)
+	assert.Equal("password", f.identities[existing.Id.ValueString()].Type)
+	assert.JSONEq(`{"password": "secret"}`, string(f.identities[existing.Id.ValueString()].RawData))
+}
+
+func TestIdentityResourceOAuth2(t *testing.T) {
+	assert := assert.New(t)
+	_, server := newFakeEventline(t)
+	r := newTestResource(server, NewIdentityResource())
+
+	for _, connector := range []string{"generic", "github"} {
+		config := newIdentityTestModel(ksuid.Generate().String(), "test", connector, "oauth2", `{"client_id": "id", "client_secret": "secret"}`)
+		resp, err := server.ValidateResourceConfig(context.Background(), &tfprotov6.ValidateResourceConfigRequest{
+			Config:   testValue(t, r.state, config),
+			TypeName: r.typeName,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal([]string{"UnsupportedIdentityType"}, diagnosticSummaries(resp.Diagnostics, tfprotov6.DiagnosticSeverityError), connector)
+	}
+}
@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Identities can be configured either with raw json data or with a typed
+// nested attribute named <connector>_<type>, generated from the identity types
+// catalog. Fields managed by eventline are not part of these attributes, and
+// oauth2 types have none since eventline refuses them through its api.
+
+func (t *IdentityType) AttributeName() string {
+	return t.Connector + "_" + t.Type
+}
+
+func (t *IdentityType) configurableFields() []IdentityTypeField {
+	var fields []IdentityTypeField
+	for _, field := range t.Fields {
+		if !field.Computed {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (t *IdentityType) schemaAttribute() schema.SingleNestedAttribute {
+	attributes := make(map[string]schema.Attribute)
+	for _, field := range t.configurableFields() {
+		switch field.Type {
+		case "boolean":
+			attributes[field.Name] = schema.BoolAttribute{
+				MarkdownDescription: field.Description,
+				Optional:            !field.Required,
+				Required:            field.Required,
+				Sensitive:           field.Sensitive,
+			}
+		case "integer":
+			attributes[field.Name] = schema.Int64Attribute{
+				MarkdownDescription: field.Description,
+				Optional:            !field.Required,
+				Required:            field.Required,
+				Sensitive:           field.Sensitive,
+			}
+		case "list(string)":
+			attributes[field.Name] = schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: field.Description,
+				Optional:            !field.Required,
+				Required:            field.Required,
+				Sensitive:           field.Sensitive,
+			}
+		default:
+			attributes[field.Name] = schema.StringAttribute{
+				MarkdownDescription: field.Description,
+				Optional:            !field.Required,
+				Required:            field.Required,
+				Sensitive:           field.Sensitive,
+			}
+		}
+	}
+	return schema.SingleNestedAttribute{
+		Attributes:          attributes,
+		MarkdownDescription: fmt.Sprintf("%s Conflicts with `data` and requires `connector` to be `%s` and `type` to be `%s`.", t.Description, t.Connector, t.Type),
+		Optional:            true,
+	}
+}
+
+func (t *IdentityType) attributeTypes() map[string]attr.Type {
+	attrTypes := make(map[string]attr.Type)
+	for _, field := range t.configurableFields() {
+		switch field.Type {
+		case "boolean":
+			attrTypes[field.Name] = types.BoolType
+		case "integer":
+			attrTypes[field.Name] = types.Int64Type
+		case "list(string)":
+			attrTypes[field.Name] = types.ListType{ElemType: types.StringType}
+		default:
+			attrTypes[field.Name] = types.StringType
+		}
+	}
+	return attrTypes
+}
+
+// EncodeObject returns the json data of an identity from the value of its
+// typed attribute. Null fields are omitted.
+func (t *IdentityType) EncodeObject(ctx context.Context, obj types.Object) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	data := make(map[string]interface{})
+	for name, value := range obj.Attributes() {
+		if value.IsNull() {
+			continue
+		}
+		switch v := value.(type) {
+		case types.Bool:
+			data[name] = v.ValueBool()
+		case types.Int64:
+			data[name] = v.ValueInt64()
+		case types.List:
+			var elements []string
+			diags.Append(v.ElementsAs(ctx, &elements, false)...)
+			data[name] = elements
+		case types.String:
+			data[name] = v.ValueString()
+		}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		diags.AddError("IdentityDataEncode", fmt.Sprintf("Unable to encode %s identity data, got error: %s", t.AttributeName(), err))
+	}
+	return encoded, diags
+}
+
+// DecodeObject returns the value of the typed attribute of an identity from
+// its json data. The prior value is used to keep fields null when eventline
+// returns their default value, and to ignore the trailing newline eventline
+// appends to keys.
+func (t *IdentityType) DecodeObject(ctx context.Context, raw []byte, prior types.Object) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	attrTypes := t.attributeTypes()
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		diags.AddError("IdentityDataDecode", fmt.Sprintf("Unable to decode %s identity data, got error: %s", t.AttributeName(), err))
+		return types.ObjectNull(attrTypes), diags
+	}
+	priorAttributes := prior.Attributes()
+	values := make(map[string]attr.Value)
+	for _, field := range t.configurableFields() {
+		values[field.Name] = decodeIdentityField(ctx, field, data[field.Name], priorAttributes[field.Name], &diags)
+	}
+	obj, d := types.ObjectValue(attrTypes, values)
+	diags.Append(d...)
+	return obj, diags
+}
+
+func decodeIdentityField(ctx context.Context, field IdentityTypeField, raw json.RawMessage, prior attr.Value, diags *diag.Diagnostics) attr.Value {
+	isMissing := len(raw) == 0 || string(raw) == "null"
+	if !isMissing && field.Default != nil && (prior == nil || prior.IsNull()) {
+		isMissing, _ = JSONRawDataEqual(raw, field.Default)
+	}
+	switch field.Type {
+	case "boolean":
+		var v bool
+		if isMissing || json.Unmarshal(raw, &v) != nil {
+			return types.BoolNull()
+		}
+		return types.BoolValue(v)
+	case "integer":
+		var v int64
+		if isMissing || json.Unmarshal(raw, &v) != nil {
+			return types.Int64Null()
+		}
+		return types.Int64Value(v)
+	case "list(string)":
+		var v []string
+		if isMissing || json.Unmarshal(raw, &v) != nil {
+			return types.ListNull(types.StringType)
+		}
+		list, d := types.ListValueFrom(ctx, types.StringType, v)
+		diags.Append(d...)
+		return list
+	default:
+		var v string
+		if isMissing || json.Unmarshal(raw, &v) != nil {
+			return types.StringNull()
+		}
+		if p, ok := prior.(types.String); ok && !p.IsNull() && p.ValueString()+"\n" == v {
+			return p
+		}
+		return types.StringValue(v)
+	}
+}
+
+// isFullyKnown returns whether an object and all its attributes, including
+// list elements, are known.
+func isFullyKnown(obj types.Object) bool {
+	if obj.IsUnknown() {
+		return false
+	}
+	for _, value := range obj.Attributes() {
+		if value.IsUnknown() {
+			return false
+		}
+		if list, ok := value.(types.List); ok {
+			for _, element := range list.Elements() {
+				if element.IsUnknown() {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// identityTypeAttributeNames returns the sorted names of all typed identity
+// attributes.
+func identityTypeAttributeNames() []string {
+	var names []string
+	for _, t := range identityTypes {
+		if !t.OAuth2 {
+			names = append(names, t.AttributeName())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func findIdentityTypeByAttributeName(name string) *IdentityType {
+	for _, t := range identityTypes {
+		if !t.OAuth2 && t.AttributeName() == name {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The catalog of identity types supported by eventline v1.1.2, as defined by
+// the connectors in pkg/connectors.
+//
+//go:embed identity_types.json
+var identityTypesData []byte
+
+var identityTypes = mustDecodeIdentityTypes(identityTypesData)
+
+type IdentityType struct {
+	Connector   string              `json:"connector"`
+	Description string              `json:"description"`
+	Fields      []IdentityTypeField `json:"fields"`
+	// OAuth2 identities are refused by the eventline api, they can only be
+	// created from the web interface.
+	OAuth2        bool     `json:"oauth2,omitempty"`
+	RequiredOneOf []string `json:"required_one_of,omitempty"`
+	Type          string   `json:"type"`
+}
+
+type IdentityTypeField struct {
+	// Computed fields are managed by eventline, for example oauth2 tokens.
+	Computed    bool            `json:"computed,omitempty"`
+	Default     json.RawMessage `json:"default,omitempty"`
+	Description string          `json:"description"`
+	Name        string          `json:"name"`
+	Required    bool            `json:"required,omitempty"`
+	Sensitive   bool            `json:"sensitive,omitempty"`
+	Type        string          `json:"type"`
+}
+
+func mustDecodeIdentityTypes(data []byte) []*IdentityType {
+	var types []*IdentityType
+	if err := json.Unmarshal(data, &types); err != nil {
+		panic(fmt.Sprintf("invalid identity types catalog: %s", err))
+	}
+	return types
+}
+
+// identityConnectors returns the sorted list of connectors having identities.
+func identityConnectors() []string {
+	var connectors []string
+	for _, t := range identityTypes {
+		connectors = append(connectors, t.Connector)
+	}
+	slices.Sort(connectors)
+	return slices.Compact(connectors)
+}
+
+// connectorIdentityTypes returns the sorted names of the identity types of a
+// connector, nil if the connector is unknown.
+func connectorIdentityTypes(connector string) []string {
+	var names []string
+	for _, t := range identityTypes {
+		if t.Connector == connector {
+			names = append(names, t.Type)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func findIdentityType(connector, name string) *IdentityType {
+	for _, t := range identityTypes {
+		if t.Connector == connector && t.Type == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (t *IdentityType) Field(name string) *IdentityTypeField {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// ValidateData checks the json data of an identity of this type and returns
+// one error per invalid field.
+func (t *IdentityType) ValidateData(data string) []error {
+	d := json.NewDecoder(strings.NewReader(data))
+	d.UseNumber()
+	var obj map[string]interface{}
+	if err := d.Decode(&obj); err != nil {
+		return []error{fmt.Errorf("invalid json object: %w", err)}
+	}
+	var errs []error
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := t.Field(key)
+		if field == nil {
+			errs = append(errs, fmt.Errorf("unknown field %q for %s/%s identities", key, t.Connector, t.Type))
+			continue
+		}
+		if err := field.validateValue(obj[key]); err != nil {
+			errs = append(errs, fmt.Errorf("invalid field %q: %w", key, err))
+		}
+	}
+	for _, field := range t.Fields {
+		if !field.Required {
+			continue
+		}
+		if value, found := obj[field.Name]; !found || isEmptyJSONValue(value) {
+			errs = append(errs, fmt.Errorf("missing required field %q", field.Name))
+		}
+	}
+	if len(t.RequiredOneOf) > 0 {
+		found := false
+		for _, name := range t.RequiredOneOf {
+			if value, ok := obj[name]; ok && !isEmptyJSONValue(value) {
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("at least one of the %s fields must be set", strings.Join(t.RequiredOneOf, ", ")))
+		}
+	}
+	return errs
+}
+
+// NormalizeData returns the json data of an identity as returned by eventline,
+// rewritten to match the prior data when they only differ by what eventline
+// adds on its own: default values, computed fields, the trailing newline of
+// keys and the order of lists. Fields are otherwise returned as is so that
+// real changes still produce a plan diff.
+func (t *IdentityType) NormalizeData(data, prior json.RawMessage) (json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	var priorObj map[string]json.RawMessage
+	if len(prior) > 0 {
+		if err := json.Unmarshal(prior, &priorObj); err != nil {
+			priorObj = nil // the prior data is replaced as a whole
+		}
+	}
+	for key, value := range obj {
+		field := t.Field(key)
+		if field == nil {
+			continue
+		}
+		priorValue, found := priorObj[key]
+		if !found {
+			if field.Computed {
+				delete(obj, key)
+			} else if field.Default != nil {
+				if equal, err := JSONRawDataEqual(value, field.Default); err == nil && equal {
+					delete(obj, key)
+				}
+			}
+			continue
+		}
+		if field.normalizedValueEqual(value, priorValue) {
+			obj[key] = priorValue
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// normalizedValueEqual returns whether a field value returned by eventline is
+// equivalent to the prior value.
+func (f *IdentityTypeField) normalizedValueEqual(value, prior json.RawMessage) bool {
+	switch f.Type {
+	case "list(string)":
+		var elements, priorElements []string
+		if json.Unmarshal(value, &elements) != nil || json.Unmarshal(prior, &priorElements) != nil {
+			return false
+		}
+		sort.Strings(elements)
+		sort.Strings(priorElements)
+		return reflect.DeepEqual(elements, priorElements)
+	case "string":
+		var s, priorString string
+		if json.Unmarshal(value, &s) != nil || json.Unmarshal(prior, &priorString) != nil {
+			return false
+		}
+		return s == priorString || s == priorString+"\n"
+	}
+	return false
+}
+
+func (f *IdentityTypeField) validateValue(value interface{}) error {
+	switch f.Type {
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean")
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an integer")
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+	case "list(string)":
+		elements, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of strings")
+		}
+		for _, element := range elements {
+			if _, ok := element.(string); !ok {
+				return fmt.Errorf("expected a list of strings")
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected a string")
+		}
+	}
+	return nil
+}
+
+func isEmptyJSONValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type IdentityTypesDataSource struct{}
+
+var _ datasource.DataSource = &IdentityTypesDataSource{} // Ensure provider defined types fully satisfy framework interfaces
+func NewIdentityTypesDataSource() datasource.DataSource {
+	return &IdentityTypesDataSource{}
+}
+
+type IdentityTypesDataSourceModel struct {
+	Connector types.String                  `tfsdk:"connector"`
+	Elements  []IdentityTypeDataSourceModel `tfsdk:"elements"`
+}
+type IdentityTypeDataSourceModel struct {
+	Connector   types.String                       `tfsdk:"connector"`
+	Description types.String                       `tfsdk:"description"`
+	Fields      []IdentityTypeFieldDataSourceModel `tfsdk:"fields"`
+	Type        types.String                       `tfsdk:"type"`
+}
+type IdentityTypeFieldDataSourceModel struct {
+	Computed    types.Bool   `tfsdk:"computed"`
+	Default     types.String `tfsdk:"default"`
+	Description types.String `tfsdk:"description"`
+	Name        types.String `tfsdk:"name"`
+	Required    types.Bool   `tfsdk:"required"`
+	Sensitive   types.Bool   `tfsdk:"sensitive"`
+	Type        types.String `tfsdk:"type"`
+}
+
+func (d *IdentityTypesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_identity_types"
+}
+
+func (d *IdentityTypesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"connector": schema.StringAttribute{
+				MarkdownDescription: "Only return identity types of this connector.",
+				Optional:            true,
+			},
+			"elements": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The list of identity types.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"connector": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The connector of the identity type.",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "A textual description of the identity type.",
+						},
+						"fields": schema.ListNestedAttribute{
+							Computed:            true,
+							MarkdownDescription: "The fields of the json data of identities of this type.",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"computed": schema.BoolAttribute{
+										Computed:            true,
+										MarkdownDescription: "Whether the field is managed by eventline or not, for example oauth2 access tokens.",
+									},
+									"default": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "The json encoded value eventline sets when the field is missing, null if none.",
+									},
+									"description": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "A textual description of the field.",
+									},
+									"name": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "The name of the field.",
+									},
+									"required": schema.BoolAttribute{
+										Computed:            true,
+										MarkdownDescription: "Whether the field is required or not.",
+									},
+									"sensitive": schema.BoolAttribute{
+										Computed:            true,
+										MarkdownDescription: "Whether the field holds a secret or not.",
+									},
+									"type": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "The type of the field, one of `boolean`, `integer`, `list(string)` or `string`.",
+									},
+								},
+							},
+						},
+						"type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the identity type.",
+						},
+					},
+				},
+			},
+		},
+		MarkdownDescription: "Use this data source to retrieve the identity types supported by eventline and the schema of their data. This catalog is embedded in the provider and does not require any api call. The `oauth2` identity types are listed but can only be created from the eventline web interface.",
+	}
+}
+
+func (d *IdentityTypesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data IdentityTypesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	typeList := make([]IdentityTypeDataSourceModel, 0, len(identityTypes))
+	for _, identityType := range identityTypes {
+		if !data.Connector.IsNull() && identityType.Connector != data.Connector.ValueString() {
+			continue
+		}
+		fields := make([]IdentityTypeFieldDataSourceModel, len(identityType.Fields))
+		for i, field := range identityType.Fields {
+			fields[i] = IdentityTypeFieldDataSourceModel{
+				Computed:    types.BoolValue(field.Computed),
+				Default:     rawJSONStringValue(field.Default),
+				Description: types.StringValue(field.Description),
+				Name:        types.StringValue(field.Name),
+				Required:    types.BoolValue(field.Required),
+				Sensitive:   types.BoolValue(field.Sensitive),
+				Type:        types.StringValue(field.Type),
+			}
+		}
+		typeList = append(typeList, IdentityTypeDataSourceModel{
+			Connector:   types.StringValue(identityType.Connector),
+			Description: types.StringValue(identityType.Description),
+			Fields:      fields,
+			Type:        types.StringValue(identityType.Type),
+		})
+	}
+	data.Elements = typeList
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityTypesCatalog(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"dockerhub", "eventline", "generic", "github", "postgresql"},
+		identityConnectors())
+
+	assert.Equal([]string{"oauth2", "token"},
+		connectorIdentityTypes("github"))
+
+	assert.Nil(connectorIdentityTypes("unknown"))
+	assert.Nil(findIdentityType("github", "personal_token"))
+}
+
+func TestIdentityTypeValidateData(t *testing.T) {
+	assert := assert.New(t)
+
+	apiKey := findIdentityType("generic", "api_key")
+	assert.Empty(apiKey.ValidateData(`{"key": "secret"}`))
+	assert.Len(apiKey.ValidateData(`{"api_key": "secret"}`), 2)
+	assert.Len(apiKey.ValidateData(`{"key": ""}`), 1)
+	assert.Len(apiKey.ValidateData(`{"key": 42}`), 1)
+	assert.Len(apiKey.ValidateData(`[]`), 1)
+
+	oauth2 := findIdentityType("github", "oauth2")
+	assert.Empty(oauth2.ValidateData(`{"client_id": "id", "client_secret": "secret", "scopes": ["repo"], "ttl": 3600}`))
+	assert.Len(oauth2.ValidateData(`{"client_id": "id", "client_secret": "secret", "scopes": "repo"}`), 1)
+	assert.Len(oauth2.ValidateData(`{"client_id": "id", "client_secret": "secret", "scopes": ["repo"], "ttl": 1.5}`), 1)
+
+	gpgKey := findIdentityType("generic", "gpg_key")
+	assert.Empty(gpgKey.ValidateData(`{"public_key": "key"}`))
+	assert.Len(gpgKey.ValidateData(`{"password": "secret"}`), 1)
+}
+
+func TestIdentityResourceTypedData(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	var model IdentityResourceModel
+	names := make([]string, 0, len(model.typedData()))
+	for name := range model.typedData() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(identityTypeAttributeNames(), names)
+	assert.NotContains(names, "generic_oauth2")
+	assert.NotContains(names, "github_oauth2")
+
+	var resp resource.SchemaResponse
+	NewIdentityResource().Schema(ctx, resource.SchemaRequest{}, &resp)
+	assert.False(resp.Diagnostics.HasError())
+	assert.False(resp.Schema.ValidateImplementation(ctx).HasError())
+}
+
+func TestIdentityTypeObjectRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	oauth2 := findIdentityType("github", "oauth2")
+	scopes, _ := types.ListValueFrom(ctx, types.StringType, []string{"repo"})
+	obj, diags := types.ObjectValue(oauth2.attributeTypes(), map[string]attr.Value{
+		"client_id":     types.StringValue("id"),
+		"client_secret": types.StringValue("secret"),
+		"scopes":        scopes,
+		"username":      types.StringNull(),
+	})
+	assert.False(diags.HasError())
+
+	data, diags := oauth2.EncodeObject(ctx, obj)
+	assert.False(diags.HasError())
+	assert.JSONEq(`{"client_id": "id", "client_secret": "secret", "scopes": ["repo"]}`, string(data))
+
+	// Eventline returns default and managed fields which must not show up
+	serverData := `{"username": "", "client_id": "id", "client_secret": "secret", "scopes": ["repo"], "access_token": "token", "ttl": 3600}`
+	decoded, diags := oauth2.DecodeObject(ctx, []byte(serverData), obj)
+	assert.False(diags.HasError())
+	assert.True(obj.Equal(decoded))
+
+	sshKey := findIdentityType("generic", "ssh_key")
+	obj, diags = types.ObjectValue(sshKey.attributeTypes(), map[string]attr.Value{
+		"certificate": types.StringNull(),
+		"private_key": types.StringValue("key"),
+		"public_key":  types.StringNull(),
+	})
+	assert.False(diags.HasError())
+	decoded, diags = sshKey.DecodeObject(ctx, []byte(`{"private_key": "key\n"}`), obj)
+	assert.False(diags.HasError())
+	assert.True(obj.Equal(decoded))
+}
+
+func TestIdentityTypeNormalizeData(t *testing.T) {
+	assert := assert.New(t)
+
+	oauth2 := findIdentityType("github", "oauth2")
+	prior := `{"client_id": "id", "client_secret": "secret", "scopes": ["repo", "user"]}`
+
+	// Default and managed fields added by eventline are dropped, reordered
+	// lists are kept as configured
+	data, err := oauth2.NormalizeData([]byte(`{"access_token": "token", "client_id": "id", "client_secret": "secret", "scopes": ["user", "repo"], "username": ""}`), []byte(prior))
+	assert.NoError(err)
+	assert.JSONEq(prior, string(data))
+
+	// Real changes are kept
+	data, err = oauth2.NormalizeData([]byte(`{"client_id": "other", "client_secret": "secret", "scopes": ["repo"], "username": "me"}`), []byte(prior))
+	assert.NoError(err)
+	assert.JSONEq(`{"client_id": "other", "client_secret": "secret", "scopes": ["repo"], "username": "me"}`, string(data))
+
+	sshKey := findIdentityType("generic", "ssh_key")
+	data, err = sshKey.NormalizeData([]byte(`{"private_key": "key\n"}`), []byte(`{"private_key": "key"}`))
+	assert.NoError(err)
+	assert.JSONEq(`{"private_key": "key"}`, string(data))
+
+	_, err = sshKey.NormalizeData([]byte(`[]`), nil)
+	assert.Error(err)
+}
@@ -0,0 +1,492 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type JobSetResource struct {
+	client *evcli.Client
+}
+
+var _ resource.Resource = &JobSetResource{}                   // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithImportState = &JobSetResource{}    // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithModifyPlan = &JobSetResource{}     // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithValidateConfig = &JobSetResource{} // Ensure provider defined types fully satisfy framework interfaces
+func NewJobSetResource() resource.Resource {
+	return &JobSetResource{}
+}
+
+type JobSetResourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	JobIds    types.Map    `tfsdk:"job_ids"`
+	Jobs      types.Map    `tfsdk:"jobs"`
+	ProjectId types.String `tfsdk:"project_id"`
+}
+
+func (r *JobSetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_job_set"
+}
+
+func (r *JobSetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The identifier of the job set, which is the identifier of its project.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"job_ids": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The identifiers of the deployed jobs, indexed like `jobs`.",
+			},
+			"jobs": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "The json specifications of the jobs to deploy, for example produced with `jsonencode(yamldecode(file(...)))`. Keys are only used to track jobs between plans, the name of each job comes from its specification: changing the name of a job while keeping its key renames it in place and preserves its execution history. All jobs are deployed atomically and jobs removed from this map are deleted.",
+				Required:            true,
+			},
+			"project_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the project the jobs are part of.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+			},
+		},
+		MarkdownDescription: "Deploys a set of eventline jobs in a single atomic operation. The specifications are validated against the server with a dry run when planning.",
+	}
+}
+
+func (r *JobSetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client, _ = req.ProviderData.(*evcli.Client)
+}
+
+func (r *JobSetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data JobSetResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Jobs.IsUnknown() || data.Jobs.IsNull() {
+		return
+	}
+	names := make(map[string]string)
+	for key, value := range data.Jobs.Elements() {
+		s, ok := value.(types.String)
+		if !ok || s.IsUnknown() {
+			continue
+		}
+		spec, err := DecodeJobSpec(s.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jobs").AtMapKey(key), "DecodeJobSpec", fmt.Sprintf("Unable to decode job specification, got error: %s", err))
+			continue
+		}
+		if other, found := names[spec.Name]; found {
+			resp.Diagnostics.AddAttributeError(path.Root("jobs").AtMapKey(key), "DuplicateJobName", fmt.Sprintf("Job name %q is also used by key %q", spec.Name, other))
+			continue
+		}
+		names[spec.Name] = key
+	}
+}
+
+func (r *JobSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+	var data JobSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.ProjectId.IsUnknown() || data.Jobs.IsUnknown() {
+		return
+	}
+	for _, value := range data.Jobs.Elements() {
+		if value.IsUnknown() {
+			return // The specifications will be validated when applying
+		}
+	}
+	keys, specs, diags := decodeJobSet(ctx, data.Jobs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || len(specs) == 0 {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	if _, err := r.client.DeployJobs(specs, true); err != nil {
+		resp.Diagnostics.Append(describeDeployJobsError(keys, err, true)...)
+	}
+}
+
+func (r *JobSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *JobSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	jobIds, diags := r.deploy(ctx, data.Jobs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Id = types.StringValue(pid.String())
+	data.JobIds, diags = types.MapValueFrom(ctx, types.StringType, jobIds)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JobSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return // The provider configuration is not known yet, the state is kept as is
+	}
+	var data *JobSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	jobs, err := r.client.FetchJobs()
+	if err != nil {
+		var e *evcli.APIError
+		if errors.As(err, &e) && e.Code == "unknown_project" {
+			resp.State.RemoveResource(ctx) // The project does not exist
+			return
+		}
+		resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs, got error: %s", err))
+		return
+	}
+	jobsById := make(map[string]*eventline.Job)
+	for _, job := range jobs {
+		jobsById[job.Id.String()] = job
+	}
+	jobIds := make(map[string]string)
+	specs := make(map[string]string)
+	if data.JobIds.IsNull() {
+		// Imported job set: adopt every job of the project indexed by name
+		for _, job := range jobs {
+			spec, err := json.Marshal(job.Spec)
+			if err != nil {
+				resp.Diagnostics.AddError("JobSpecMarshal", fmt.Sprintf("Unable to encode job specification, got error: %s", err))
+				return
+			}
+			jobIds[job.Spec.Name] = job.Id.String()
+			specs[job.Spec.Name] = string(spec)
+		}
+	} else {
+		var stateJobIds, stateSpecs map[string]string
+		resp.Diagnostics.Append(data.JobIds.ElementsAs(ctx, &stateJobIds, false)...)
+		resp.Diagnostics.Append(data.Jobs.ElementsAs(ctx, &stateSpecs, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for key, id := range stateJobIds {
+			job, found := jobsById[id]
+			if !found {
+				continue // The job was deleted outside of terraform
+			}
+			jobIds[key] = id
+			specs[key] = stateSpecs[key]
+			if stateSpec, err := DecodeJobSpec(stateSpecs[key]); err == nil {
+				if equal, _ := JobSpecsEqual(stateSpec, job.Spec); equal {
+					continue // Keep the configuration formatting when nothing changed
+				}
+			}
+			spec, err := json.Marshal(job.Spec)
+			if err != nil {
+				resp.Diagnostics.AddError("JobSpecMarshal", fmt.Sprintf("Unable to encode job specification, got error: %s", err))
+				return
+			}
+			specs[key] = string(spec)
+		}
+	}
+	var diags diag.Diagnostics
+	data.Id = types.StringValue(pid.String())
+	data.JobIds, diags = types.MapValueFrom(ctx, types.StringType, jobIds)
+	resp.Diagnostics.Append(diags...)
+	data.Jobs, diags = types.MapValueFrom(ctx, types.StringType, specs)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JobSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state *JobSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	var stateJobIds map[string]string
+	resp.Diagnostics.Append(state.JobIds.ElementsAs(ctx, &stateJobIds, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// On failure the prior state is kept: it still references the jobs by id,
+	// and refreshing it reports the names they were left with.
+	deleted, diags := r.rename(ctx, stateJobIds, state.Jobs, data.Jobs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+	jobIds, diags := r.deploy(ctx, data.Jobs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+	deployed := make(map[string]bool)
+	for _, id := range jobIds {
+		deployed[id] = true
+	}
+	for _, id := range stateJobIds {
+		if deployed[id] || deleted[id] {
+			continue
+		}
+		if err := r.client.DeleteJob(id); err != nil {
+			var e *evcli.APIError
+			if errors.As(err, &e) && e.Code == "unknown_job" {
+				continue // the job does not exist, that is what we want
+			}
+			resp.Diagnostics.AddError("DeleteJob", fmt.Sprintf("Unable to delete job %s, got error: %s", id, err))
+		}
+	}
+	data.Id = types.StringValue(pid.String())
+	data.JobIds, diags = types.MapValueFrom(ctx, types.StringType, jobIds)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JobSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *JobSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	var jobIds map[string]string
+	resp.Diagnostics.Append(data.JobIds.ElementsAs(ctx, &jobIds, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, id := range jobIds {
+		if err := r.client.DeleteJob(id); err != nil {
+			var e *evcli.APIError
+			if errors.As(err, &e) && (e.Code == "unknown_job" || e.Code == "unknown_project") {
+				continue // the job does not exist, that is what we want
+			}
+			resp.Diagnostics.AddError("DeleteJob", fmt.Sprintf("Unable to delete job %s, got error: %s", id, err))
+		}
+	}
+}
+
+func (r *JobSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("project_id"), req.ID)...)
+}
+
+// deploy sends all job specifications in a single DeployJobs call and returns
+// the identifiers of the resulting jobs indexed by key.
+func (r *JobSetResource) deploy(ctx context.Context, jobs types.Map) (map[string]string, diag.Diagnostics) {
+	keys, specs, diags := decodeJobSet(ctx, jobs)
+	if diags.HasError() {
+		return nil, diags
+	}
+	deployed, err := r.client.DeployJobs(specs, false)
+	if err != nil {
+		diags.Append(describeDeployJobsError(keys, err, false)...)
+		return nil, diags
+	}
+	if len(deployed) != len(keys) {
+		diags.AddError("DeployJobs", fmt.Sprintf("Expected %d deployed jobs, got %d", len(keys), len(deployed)))
+		return nil, diags
+	}
+	jobIds := make(map[string]string)
+	for i, job := range deployed {
+		jobIds[keys[i]] = job.Id.String()
+	}
+	return jobIds, diags
+}
+
+// rename renames in place the jobs whose specification name changed under the
+// same key, so that the following deployment updates them instead of creating
+// new jobs and their execution history is preserved. Jobs removed from the set
+// are deleted first when their name is taken by a renamed job. When a new name
+// is still held by a job of the set, for example when two jobs swap their
+// names, all renamed jobs are first moved to temporary names. It returns the
+// identifiers of the deleted jobs.
+func (r *JobSetResource) rename(ctx context.Context, jobIds map[string]string, stateJobs, planJobs types.Map) (map[string]bool, diag.Diagnostics) {
+	stateKeys, stateSpecs, diags := decodeJobSet(ctx, stateJobs)
+	if diags.HasError() {
+		return nil, diags
+	}
+	keys, planSpecs, diags := decodeJobSet(ctx, planJobs)
+	if diags.HasError() {
+		return nil, diags
+	}
+	stateNames := make(map[string]string)
+	heldNames := make(map[string]bool)
+	for i, key := range stateKeys {
+		stateNames[key] = stateSpecs[i].Name
+		heldNames[stateSpecs[i].Name] = true
+	}
+	var renamedKeys []string
+	renamings := make(map[string]*eventline.JobRenamingData)
+	newNames := make(map[string]bool)
+	for i, key := range keys {
+		if _, found := jobIds[key]; !found {
+			continue // New job
+		}
+		name, found := stateNames[key]
+		if !found || name == planSpecs[i].Name {
+			continue
+		}
+		renamedKeys = append(renamedKeys, key)
+		renamings[key] = &eventline.JobRenamingData{
+			Name:        planSpecs[i].Name,
+			Description: planSpecs[i].Description,
+		}
+		newNames[planSpecs[i].Name] = true
+	}
+	planKeys := make(map[string]bool)
+	for _, key := range keys {
+		planKeys[key] = true
+	}
+	deleted := make(map[string]bool)
+	for _, key := range stateKeys {
+		id, found := jobIds[key]
+		if !found || planKeys[key] || !newNames[stateNames[key]] {
+			continue
+		}
+		if err := r.client.DeleteJob(id); err != nil {
+			var e *evcli.APIError
+			if !errors.As(err, &e) || e.Code != "unknown_job" {
+				diags.AddError("DeleteJob", fmt.Sprintf("Unable to delete job %s to free its name %q, got error: %s", id, stateNames[key], err))
+				return deleted, diags
+			}
+		}
+		deleted[id] = true
+		delete(heldNames, stateNames[key])
+	}
+	temporary := false
+	for name := range newNames {
+		temporary = temporary || heldNames[name]
+	}
+	if temporary {
+		for _, key := range renamedKeys {
+			data := eventline.JobRenamingData{
+				Name:        temporaryJobName(jobIds[key]),
+				Description: renamings[key].Description,
+			}
+			if err := r.client.RenameJob(jobIds[key], &data); err != nil {
+				diags.AddAttributeError(path.Root("jobs").AtMapKey(key), "RenameJob", fmt.Sprintf("Unable to rename job %q to temporary name %q, got error: %s", stateNames[key], data.Name, err))
+				return deleted, diags
+			}
+		}
+	}
+	for _, key := range renamedKeys {
+		if err := r.client.RenameJob(jobIds[key], renamings[key]); err != nil {
+			diags.AddAttributeError(path.Root("jobs").AtMapKey(key), "RenameJob", fmt.Sprintf("Unable to rename job %q to %q, got error: %s", stateNames[key], renamings[key].Name, err))
+			return deleted, diags
+		}
+	}
+	return deleted, diags
+}
+
+// temporaryJobName returns a valid job name derived from a job id, used to free
+// the name of a job while renaming a job set.
+func temporaryJobName(id string) string {
+	return "terraform-rename-" + strings.ToLower(id)
+}
+
+// decodeJobSet returns the keys and decoded specifications of a jobs map,
+// sorted by key so that deployments are deterministic.
+func decodeJobSet(ctx context.Context, jobs types.Map) ([]string, []*eventline.JobSpec, diag.Diagnostics) {
+	var raw map[string]string
+	diags := jobs.ElementsAs(ctx, &raw, false)
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	specs := make([]*eventline.JobSpec, len(keys))
+	for i, key := range keys {
+		spec, err := DecodeJobSpec(raw[key])
+		if err != nil {
+			diags.AddAttributeError(path.Root("jobs").AtMapKey(key), "DecodeJobSpec", fmt.Sprintf("Unable to decode job specification, got error: %s", err))
+			continue
+		}
+		specs[i] = spec
+	}
+	return keys, specs, diags
+}
+
+// describeDeployJobsError maps server side validation errors, whose json
+// pointers start with the index of the job specification, back to the jobs
+// map keys. When planning, unknown identities are only reported as warnings
+// since they can be created during the same apply.
+func describeDeployJobsError(keys []string, err error, planning bool) (diags diag.Diagnostics) {
+	ok, validationErrors := evcli.IsInvalidRequestBodyError(err)
+	if !ok {
+		diags.AddError("DeployJobs", fmt.Sprintf("Unable to deploy jobs, got error: %s", err))
+		return
+	}
+	for _, verr := range validationErrors {
+		attributePath := path.Root("jobs")
+		pointer := verr.Pointer
+		if len(pointer) > 0 {
+			var i int
+			if _, scanErr := fmt.Sscanf(pointer[0], "%d", &i); scanErr == nil && i >= 0 && i < len(keys) {
+				attributePath = attributePath.AtMapKey(keys[i])
+				pointer = pointer[1:]
+			}
+		}
+		detail := fmt.Sprintf("Invalid job specification at /%s: %s", strings.Join(pointer, "/"), verr.Message)
+		if planning && verr.Code == "unknown_identity" {
+			diags.AddAttributeWarning(attributePath, "DeployJobs", detail)
+			continue
+		}
+		diags.AddAttributeError(attributePath, "DeployJobs", detail)
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+)
+
+// newJobSetTestModel returns a job set whose jobs are named by key.
+func newJobSetTestModel(t *testing.T, projectId string, names map[string]string) *JobSetResourceModel {
+	specs := make(map[string]string)
+	for key, name := range names {
+		specs[key] = fmt.Sprintf(`{"name": %q}`, name)
+	}
+	jobs, diags := types.MapValueFrom(context.Background(), types.StringType, specs)
+	if diags.HasError() {
+		t.Fatalf("cannot build jobs: %v", diags)
+	}
+	return &JobSetResourceModel{
+		Id:        types.StringNull(),
+		JobIds:    types.MapNull(types.StringType),
+		Jobs:      jobs,
+		ProjectId: types.StringValue(projectId),
+	}
+}
+
+// jobSetChange returns the prior, config and proposed values of a change of
+// job set, computed attributes being kept from the prior model.
+func jobSetChange(prior, config *JobSetResourceModel) (interface{}, interface{}, interface{}) {
+	proposed := *config
+	proposed.Id = prior.Id
+	proposed.JobIds = prior.JobIds
+	return prior, config, &proposed
+}
+
+func TestJobSetResourceRename(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewJobSetResource())
+
+	projectId := ksuid.Generate().String()
+	var jobSet *JobSetResourceModel
+	config := newJobSetTestModel(t, projectId, map[string]string{"a": "x", "b": "y"})
+	checkDiagnostics(t, r.apply(t, nil, config, config, &jobSet))
+	var jobIds map[string]string
+	assert.False(jobSet.JobIds.ElementsAs(context.Background(), &jobIds, false).HasError())
+	f.takeRequests()
+
+	// Swapping names goes through temporary names
+	var swapped *JobSetResourceModel
+	prior, config2, proposed := jobSetChange(jobSet, newJobSetTestModel(t, projectId, map[string]string{"a": "y", "b": "x"}))
+	checkDiagnostics(t, r.apply(t, prior, config2, proposed, &swapped))
+	assert.Equal([]string{
+		"PUT /jobs",
+		"POST /jobs/id/" + jobIds["a"] + "/rename",
+		"POST /jobs/id/" + jobIds["b"] + "/rename",
+		"POST /jobs/id/" + jobIds["a"] + "/rename",
+		"POST /jobs/id/" + jobIds["b"] + "/rename",
+		"PUT /jobs",
+	}, f.takeRequests())
+	assert.Equal(jobSet.JobIds, swapped.JobIds)
+	assert.Equal(jobIds["a"], f.findJobByName(projectId, "y").Id.String())
+	assert.Equal(jobIds["b"], f.findJobByName(projectId, "x").Id.String())
+
+	// Renaming a job to the name of a removed job deletes the latter first
+	var reduced *JobSetResourceModel
+	prior, config2, proposed = jobSetChange(swapped, newJobSetTestModel(t, projectId, map[string]string{"a": "x"}))
+	checkDiagnostics(t, r.apply(t, prior, config2, proposed, &reduced))
+	assert.Equal([]string{
+		"PUT /jobs",
+		"DELETE /jobs/id/" + jobIds["b"],
+		"POST /jobs/id/" + jobIds["a"] + "/rename",
+		"PUT /jobs",
+	}, f.takeRequests())
+	var reducedJobIds map[string]string
+	assert.False(reduced.JobIds.ElementsAs(context.Background(), &reducedJobIds, false).HasError())
+	assert.Equal(map[string]string{"a": jobIds["a"]}, reducedJobIds)
+	assert.Len(f.jobs[projectId], 1)
+	assert.Equal(jobIds["a"], f.findJobByName(projectId, "x").Id.String())
+	swapped = reduced
+
+	// A failed rename keeps the prior state
+	f.addJob(projectId, &eventline.JobSpec{Name: "z"})
+	prior, config2, proposed = jobSetChange(swapped, newJobSetTestModel(t, projectId, map[string]string{"a": "z"}))
+	var failed *JobSetResourceModel
+	diags := r.apply(t, prior, config2, proposed, &failed)
+	assert.Equal([]string{"RenameJob"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	assert.Equal(swapped, failed)
+	assert.Len(f.jobs[projectId], 2)
+}
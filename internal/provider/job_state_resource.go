@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The private state key of the disabled flag a job had before being managed by
+// terraform.
+const jobStatePreviousDisabledKey = "previous_disabled"
+
+type JobStateResource struct {
+	client *evcli.Client
+}
+
+var _ resource.Resource = &JobStateResource{}                // Ensure provider defined types fully satisfy framework interfaces
+var _ resource.ResourceWithImportState = &JobStateResource{} // Ensure provider defined types fully satisfy framework interfaces
+func NewJobStateResource() resource.Resource {
+	return &JobStateResource{}
+}
+
+type JobStateResourceModel struct {
+	Disabled  types.Bool   `tfsdk:"disabled"`
+	Id        types.String `tfsdk:"id"`
+	Name      types.String `tfsdk:"name"`
+	ProjectId types.String `tfsdk:"project_id"`
+}
+
+func (r *JobStateResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_job_state"
+}
+
+func (r *JobStateResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the job is disabled or not.",
+				Required:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The identifier of the job. It changes when the job is deleted and deployed again, in which case its disabled flag is enforced anew.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the job. Changing it forces the resource to be replaced.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+			},
+			"project_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the project the job is part of. Changing it forces the resource to be replaced.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+			},
+		},
+		MarkdownDescription: "Keeps the disabled flag of an existing eventline job under terraform control. The job is looked up by name so that the flag is enforced again if the job is redeployed. Destroying this resource restores the disabled flag the job had before being managed by terraform, imported jobs being left in their current state.",
+	}
+}
+
+func (r *JobStateResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client, _ = req.ProviderData.(*evcli.Client)
+}
+
+func (r *JobStateResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *JobStateResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	previous, diags := r.apply(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, jobStatePreviousDisabledKey, []byte(strconv.FormatBool(previous)))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JobStateResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return // The provider configuration is not known yet, the state is kept as is
+	}
+	var data *JobStateResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	job, err := r.client.FetchJobByName(data.Name.ValueString())
+	if err != nil {
+		var e *evcli.APIError
+		if errors.As(err, &e) && e.Code == "unknown_job" {
+			resp.State.RemoveResource(ctx) // The job does not exist
+			return
+		}
+		resp.Diagnostics.AddError("FetchJobByName", fmt.Sprintf("Unable to fetch job by name, got error: %s", err))
+		return
+	}
+	data.Disabled = types.BoolValue(job.Disabled)
+	data.Id = types.StringValue(job.Id.String())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JobStateResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data *JobStateResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := r.apply(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JobStateResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *JobStateResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	previous, diags := req.Private.GetKey(ctx, jobStatePreviousDisabledKey)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || previous == nil {
+		return // The job was imported, it is left in its current state
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	job, err := r.client.FetchJobByName(data.Name.ValueString())
+	if err != nil {
+		var e *evcli.APIError
+		if errors.As(err, &e) && (e.Code == "unknown_job" || e.Code == "unknown_project") {
+			return // the job does not exist anymore, there is nothing to restore
+		}
+		resp.Diagnostics.AddError("FetchJobByName", fmt.Sprintf("Unable to fetch job by name, got error: %s", err))
+		return
+	}
+	if err := r.setDisabled(job, string(previous) == "true"); err != nil {
+		resp.Diagnostics.AddError("SetJobDisabled", fmt.Sprintf("Unable to restore job disabled flag, got error: %s", err))
+		return
+	}
+}
+
+func (r *JobStateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	idParts := strings.Split(req.ID, "/")
+	if len(idParts) != 2 || idParts[0] == "" || idParts[1] == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: projectID/jobName. Got: %q", req.ID),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("project_id"), idParts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), idParts[1])...)
+}
+
+// apply looks up the job by name, which resolves its current id even if it
+// was redeployed, then enables or disables it as needed. It returns the flag
+// the job had before.
+func (r *JobStateResource) apply(data *JobStateResourceModel) (previous bool, diags diag.Diagnostics) {
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		diags.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	r.client.ProjectId = &pid
+	job, err := r.client.FetchJobByName(data.Name.ValueString())
+	if err != nil {
+		diags.AddError("FetchJobByName", fmt.Sprintf("Unable to fetch job by name, got error: %s", err))
+		return
+	}
+	previous = job.Disabled
+	if err := r.setDisabled(job, data.Disabled.ValueBool()); err != nil {
+		diags.AddError("SetJobDisabled", fmt.Sprintf("Unable to change job disabled flag, got error: %s", err))
+		return
+	}
+	data.Id = types.StringValue(job.Id.String())
+	return
+}
+
+func (r *JobStateResource) setDisabled(job *eventline.Job, disabled bool) error {
+	if job.Disabled == disabled {
+		return nil
+	}
+	if disabled {
+		return r.client.DisableJob(job.Id.String())
+	}
+	return r.client.EnableJob(job.Id.String())
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobStateResourceReplacement(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewJobStateResource())
+
+	projectId := ksuid.Generate().String()
+	first := f.addJob(projectId, &eventline.JobSpec{Name: "first"})
+	second := f.addJob(projectId, &eventline.JobSpec{Name: "second"})
+
+	config := &JobStateResourceModel{
+		Disabled:  types.BoolValue(true),
+		Id:        types.StringNull(),
+		Name:      types.StringValue("first"),
+		ProjectId: types.StringValue(projectId),
+	}
+	var state *JobStateResourceModel
+	checkDiagnostics(t, r.apply(t, nil, config, config, &state))
+	assert.True(first.Disabled)
+
+	// Managing another job replaces the resource, restoring the first job
+	config.Name = types.StringValue("second")
+	proposed := *config
+	proposed.Id = state.Id
+	plan := r.plan(t, state, config, &proposed)
+	checkDiagnostics(t, plan.Diagnostics)
+	assert.Equal([]*tftypes.AttributePath{tftypes.NewAttributePath().WithAttributeName("name")}, plan.RequiresReplace)
+
+	checkDiagnostics(t, r.apply(t, state, nil, nil, nil))
+	assert.False(first.Disabled)
+	checkDiagnostics(t, r.apply(t, nil, config, config, &state))
+	assert.True(second.Disabled)
+	assert.Equal(second.Id.String(), state.Id.ValueString())
+}
+
+func TestJobStateResourceUpdate(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewJobStateResource())
+
+	projectId := ksuid.Generate().String()
+	job := f.addJob(projectId, &eventline.JobSpec{Name: "job"})
+
+	config := &JobStateResourceModel{
+		Disabled:  types.BoolValue(true),
+		Id:        types.StringNull(),
+		Name:      types.StringValue("job"),
+		ProjectId: types.StringValue(projectId),
+	}
+	var state *JobStateResourceModel
+	checkDiagnostics(t, r.apply(t, nil, config, config, &state))
+
+	// Changing the disabled flag keeps the id known when planning
+	config.Disabled = types.BoolValue(false)
+	proposed := *config
+	proposed.Id = state.Id
+	plan := r.plan(t, state, config, &proposed)
+	checkDiagnostics(t, plan.Diagnostics)
+	var planned *JobStateResourceModel
+	decodeTestValue(t, r.state, plan.PlannedState, &planned)
+	assert.Equal(state.Id, planned.Id)
+
+	checkDiagnostics(t, r.apply(t, state, config, &proposed, &state))
+	assert.False(job.Disabled)
+	assert.Equal(job.Id.String(), state.Id.ValueString())
+}
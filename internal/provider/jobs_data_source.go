@@ -2,12 +2,16 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
 
 	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/eventline"
 	"github.com/exograd/eventline/pkg/ksuid"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -21,8 +25,14 @@ func NewJobsDataSource() datasource.DataSource {
 }
 
 type JobsDataSourceModel struct {
-	Elements  []JobDataSourceModel `tfsdk:"elements"`
-	ProjectId types.String         `tfsdk:"project_id"`
+	ByName           map[string]JobDataSourceModel `tfsdk:"by_name"`
+	Disabled         types.Bool                    `tfsdk:"disabled"`
+	Elements         []JobDataSourceModel          `tfsdk:"elements"`
+	Identity         types.String                  `tfsdk:"identity"`
+	NameRegex        types.String                  `tfsdk:"name_regex"`
+	ProjectId        types.String                  `tfsdk:"project_id"`
+	TriggerConnector types.String                  `tfsdk:"trigger_connector"`
+	TriggerEvent     types.String                  `tfsdk:"trigger_event"`
 }
 type JobDataSourceModel struct {
 	Disabled types.Bool             `tfsdk:"disabled"`
@@ -41,7 +51,15 @@ type JobSpecDataSourceModel struct {
 	Trigger     *TriggerDataSourceModel    `tfsdk:"trigger"`
 	Steps       []StepDataSourceModel      `tfsdk:"steps"`
 }
+type FilterDataSourceModel struct {
+	DoesNotMatch types.String `tfsdk:"does_not_match"`
+	IsEqualTo    types.String `tfsdk:"is_equal_to"`
+	IsNotEqualTo types.String `tfsdk:"is_not_equal_to"`
+	Matches      types.String `tfsdk:"matches"`
+	Path         types.String `tfsdk:"path"`
+}
 type ParameterDataSourceModel struct {
+	Default     types.String `tfsdk:"default"`
 	Description types.String `tfsdk:"description"`
 	Environment types.String `tfsdk:"environment"`
 	Name        types.String `tfsdk:"name"`
@@ -49,14 +67,16 @@ type ParameterDataSourceModel struct {
 	Values      types.List   `tfsdk:"values"`
 }
 type RunnerDataSourceModel struct {
-	Name     types.String `tfsdk:"name"`
-	Identity types.String `tfsdk:"identity"`
+	Name       types.String `tfsdk:"name"`
+	Identity   types.String `tfsdk:"identity"`
+	Parameters types.String `tfsdk:"parameters"`
 }
 type StepDataSourceModel struct {
-	Code    types.String                `tfsdk:"code"`
-	Command *StepCommandDataSourceModel `tfsdk:"command"`
-	Label   types.String                `tfsdk:"label"`
-	Script  *StepScriptDataSourceModel  `tfsdk:"script"`
+	Code      types.String                `tfsdk:"code"`
+	Command   *StepCommandDataSourceModel `tfsdk:"command"`
+	Label     types.String                `tfsdk:"label"`
+	OnFailure types.String                `tfsdk:"on_failure"`
+	Script    *StepScriptDataSourceModel  `tfsdk:"script"`
 }
 type StepCommandDataSourceModel struct {
 	Arguments types.List   `tfsdk:"arguments"`
@@ -68,8 +88,12 @@ type StepScriptDataSourceModel struct {
 	Path      types.String `tfsdk:"path"`
 }
 type TriggerDataSourceModel struct {
-	Event    types.String `tfsdk:"event"`
-	Identity types.String `tfsdk:"identity"`
+	Connector  types.String            `tfsdk:"connector"`
+	Event      types.String            `tfsdk:"event"`
+	EventName  types.String            `tfsdk:"event_name"`
+	Filters    []FilterDataSourceModel `tfsdk:"filters"`
+	Identity   types.String            `tfsdk:"identity"`
+	Parameters types.String            `tfsdk:"parameters"`
 }
 
 func (d *JobsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -77,166 +101,244 @@ func (d *JobsDataSource) Metadata(ctx context.Context, req datasource.MetadataRe
 }
 
 func (d *JobsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
-	resp.Schema = schema.Schema{
+	jobObject := schema.NestedAttributeObject{
 		Attributes: map[string]schema.Attribute{
-			"elements": schema.ListNestedAttribute{
+			"disabled": schema.BoolAttribute{
 				Computed:            true,
-				MarkdownDescription: "The list of jobs.",
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"disabled": schema.BoolAttribute{
-							Computed:            true,
-							MarkdownDescription: "Whether the job is disabled or not.",
-						},
-						"id": schema.StringAttribute{
-							Computed:            true,
-							MarkdownDescription: "The identifier of the job.",
-						},
-						"spec": schema.SingleNestedAttribute{
+				MarkdownDescription: "Whether the job is disabled or not.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The identifier of the job.",
+			},
+			"spec": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"concurrent": schema.BoolAttribute{
+						Computed:            true,
+						MarkdownDescription: "Whether to allow concurrent executions for this job or not.",
+					},
+					"description": schema.StringAttribute{
+						Computed:            true,
+						MarkdownDescription: "A textual description of the job.",
+					},
+					"environment": schema.MapAttribute{
+						ElementType:         types.StringType,
+						Computed:            true,
+						MarkdownDescription: "A set of environment variables mapping names to values to be defined during job execution.",
+					},
+					"identities": schema.SetAttribute{
+						Computed:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "Set of eventline identities names to inject during job execution.",
+					},
+					"name": schema.StringAttribute{
+						Computed:            true,
+						MarkdownDescription: "The name of the job.",
+					},
+					"parameters": schema.ListNestedAttribute{
+						Computed: true,
+						NestedObject: schema.NestedAttributeObject{
 							Attributes: map[string]schema.Attribute{
-								"concurrent": schema.BoolAttribute{
+								"default": schema.StringAttribute{
 									Computed:            true,
-									MarkdownDescription: "Whether to allow concurrent executions for this job or not.",
+									MarkdownDescription: "The json encoded default value of the parameter, null if the parameter has no default value.",
 								},
 								"description": schema.StringAttribute{
 									Computed:            true,
-									MarkdownDescription: "A textual description of the job.",
-								},
-								"environment": schema.MapAttribute{
-									ElementType:         types.StringType,
-									Computed:            true,
-									MarkdownDescription: "A set of environment variables mapping names to values to be defined during job execution.",
+									MarkdownDescription: "A textual description of the parameter.",
 								},
-								"identities": schema.SetAttribute{
+								"environment": schema.StringAttribute{
 									Computed:            true,
-									ElementType:         types.StringType,
-									MarkdownDescription: "Set of eventline identities names to inject during job execution.",
+									MarkdownDescription: "The name of an environment variable to be used to inject the value of this parameter during execution.",
 								},
 								"name": schema.StringAttribute{
 									Computed:            true,
-									MarkdownDescription: "The name of the job.",
+									MarkdownDescription: "The name of the parameter.",
 								},
-								"parameters": schema.ListNestedAttribute{
-									Computed: true,
-									NestedObject: schema.NestedAttributeObject{
-										Attributes: map[string]schema.Attribute{
-											"description": schema.StringAttribute{
-												Computed:            true,
-												MarkdownDescription: "A textual description of the parameter.",
-											},
-											"environment": schema.StringAttribute{
-												Computed:            true,
-												MarkdownDescription: "The name of an environment variable to be used to inject the value of this parameter during execution.",
-											},
-											"name": schema.StringAttribute{
-												Computed:            true,
-												MarkdownDescription: "The name of the parameter.",
-											},
-											"type": schema.StringAttribute{
-												Computed:            true,
-												MarkdownDescription: "The type of the parameter. The following types are supported:\n  - number: Either an integer or an IEEE 754 double precision floating point value.\n  - integer: An integer.\n  - string: A character string.\n  - boolean: A boolean.",
-											},
-											"values": schema.ListAttribute{
-												Computed:            true,
-												ElementType:         types.StringType,
-												MarkdownDescription: "For parameters of type string, the list of valid values.",
-											},
-										},
-									},
+								"type": schema.StringAttribute{
+									Computed:            true,
+									MarkdownDescription: "The type of the parameter. The following types are supported:\n  - number: Either an integer or an IEEE 754 double precision floating point value.\n  - integer: An integer.\n  - string: A character string.\n  - boolean: A boolean.",
 								},
-								"retention": schema.Int64Attribute{
+								"values": schema.ListAttribute{
 									Computed:            true,
-									MarkdownDescription: "The number of days after which past executions of this job will be deleted. This value override the global job_retention setting.",
+									ElementType:         types.StringType,
+									MarkdownDescription: "For parameters of type string, the list of valid values.",
 								},
-								"runner": schema.SingleNestedAttribute{
+							},
+						},
+					},
+					"retention": schema.Int64Attribute{
+						Computed:            true,
+						MarkdownDescription: "The number of days after which past executions of this job will be deleted. This value override the global job_retention setting.",
+					},
+					"runner": schema.SingleNestedAttribute{
+						Attributes: map[string]schema.Attribute{
+							"name": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The name of the runner.",
+							},
+							"identity": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The name of an identity to use for runners which require authentication. For example the ssh runner needs an identity to initiate an ssh connection.",
+							},
+							"parameters": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The json encoded parameters of the runner, which depend on the runner used.",
+							},
+						},
+						Computed:            true,
+						MarkdownDescription: "The specification of the runner used to execute the job.",
+					},
+					"trigger": schema.SingleNestedAttribute{
+						Attributes: map[string]schema.Attribute{
+							"connector": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The connector of the event to react to.",
+							},
+							"event": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The event to react to formatted as <connector>/<event>.",
+							},
+							"event_name": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The name of the event to react to.",
+							},
+							"filters": schema.ListNestedAttribute{
+								Computed:            true,
+								MarkdownDescription: "A list of filters all of which must match for the event to trigger the job.",
+								NestedObject: schema.NestedAttributeObject{
 									Attributes: map[string]schema.Attribute{
-										"name": schema.StringAttribute{
+										"does_not_match": schema.StringAttribute{
+											Computed:            true,
+											MarkdownDescription: "A regular expression the value must not match.",
+										},
+										"is_equal_to": schema.StringAttribute{
 											Computed:            true,
-											MarkdownDescription: "The name of the runner.",
+											MarkdownDescription: "The json encoded value the value must be equal to.",
 										},
-										"identity": schema.StringAttribute{
+										"is_not_equal_to": schema.StringAttribute{
 											Computed:            true,
-											MarkdownDescription: "The name of an identity to use for runners which require authentication. For example the ssh runner needs an identity to initiate an ssh connection.",
+											MarkdownDescription: "The json encoded value the value must not be equal to.",
+										},
+										"matches": schema.StringAttribute{
+											Computed:            true,
+											MarkdownDescription: "A regular expression the value must match.",
+										},
+										"path": schema.StringAttribute{
+											Computed:            true,
+											MarkdownDescription: "The json pointer of the value in the event data.",
 										},
 									},
+								},
+							},
+							"identity": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The name of an identity to use for events which require authentication. For example the github/push event needs an identity to create the GitHub hook used to listen to push events.",
+							},
+							"parameters": schema.StringAttribute{
+								Computed:            true,
+								MarkdownDescription: "The json encoded parameters of the trigger, which depend on the event. For example the period of a time/tick event or the organization and repository of a github/push event.",
+							},
+						},
+						Computed:            true,
+						MarkdownDescription: "The specification of a trigger indicating when to execute the job.",
+					},
+					"steps": schema.ListNestedAttribute{
+						Computed:            true,
+						MarkdownDescription: "A list of steps which will be executed sequentially.",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"code": schema.StringAttribute{
 									Computed:            true,
-									MarkdownDescription: "The specification of the runner used to execute the job.",
+									MarkdownDescription: "The fragment of code to execute for this step.",
 								},
-								"trigger": schema.SingleNestedAttribute{
+								"command": schema.SingleNestedAttribute{
 									Attributes: map[string]schema.Attribute{
-										"event": schema.StringAttribute{
+										"arguments": schema.ListAttribute{
 											Computed:            true,
-											MarkdownDescription: "The event to react to formatted as <connector>/<event>.",
+											ElementType:         types.StringType,
+											MarkdownDescription: "The list of arguments to pass to the command.",
 										},
-										"identity": schema.StringAttribute{
+										"name": schema.StringAttribute{
 											Computed:            true,
-											MarkdownDescription: "The name of an identity to use for events which require authentication. For example the github/push event needs an identity to create the GitHub hook used to listen to push events.",
+											MarkdownDescription: "The name of the command.",
 										},
 									},
 									Computed:            true,
-									MarkdownDescription: "The specification of a trigger indicating when to execute the job.",
+									MarkdownDescription: "The command to execute for this step.",
 								},
-								"steps": schema.ListNestedAttribute{
+								"label": schema.StringAttribute{
 									Computed:            true,
-									MarkdownDescription: "A list of steps which will be executed sequentially.",
-									NestedObject: schema.NestedAttributeObject{
-										Attributes: map[string]schema.Attribute{
-											"code": schema.StringAttribute{
-												Computed:            true,
-												MarkdownDescription: "The fragment of code to execute for this step.",
-											},
-											"command": schema.SingleNestedAttribute{
-												Attributes: map[string]schema.Attribute{
-													"arguments": schema.ListAttribute{
-														Computed:            true,
-														ElementType:         types.StringType,
-														MarkdownDescription: "The list of arguments to pass to the command.",
-													},
-													"name": schema.StringAttribute{
-														Computed:            true,
-														MarkdownDescription: "The name of the command.",
-													},
-												},
-												Computed:            true,
-												MarkdownDescription: "The command to execute for this step.",
-											},
-											"label": schema.StringAttribute{
-												Computed:            true,
-												MarkdownDescription: "A short description of the step which will be displayed on the web interface.",
-											},
-											"script": schema.SingleNestedAttribute{
-												Attributes: map[string]schema.Attribute{
-													"arguments": schema.ListAttribute{
-														Computed:            true,
-														ElementType:         types.StringType,
-														MarkdownDescription: "The list of arguments to pass to the script.",
-													},
-													"content": schema.StringAttribute{
-														Computed:            true,
-														MarkdownDescription: "The script file contents.",
-													},
-													"path": schema.StringAttribute{
-														Computed:            true,
-														MarkdownDescription: "The path of the script file relative to the job file.",
-													},
-												},
-												Computed:            true,
-												MarkdownDescription: "The command to execute for this step.",
-											},
+									MarkdownDescription: "A short description of the step which will be displayed on the web interface.",
+								},
+								"on_failure": schema.StringAttribute{
+									Computed:            true,
+									MarkdownDescription: "What to do when the step fails, either `abort` or `continue`.",
+								},
+								"script": schema.SingleNestedAttribute{
+									Attributes: map[string]schema.Attribute{
+										"arguments": schema.ListAttribute{
+											Computed:            true,
+											ElementType:         types.StringType,
+											MarkdownDescription: "The list of arguments to pass to the script.",
+										},
+										"content": schema.StringAttribute{
+											Computed:            true,
+											MarkdownDescription: "The script file contents.",
+										},
+										"path": schema.StringAttribute{
+											Computed:            true,
+											MarkdownDescription: "The path of the script file relative to the job file.",
 										},
 									},
+									Computed:            true,
+									MarkdownDescription: "The command to execute for this step.",
 								},
 							},
-							Computed:            true,
-							MarkdownDescription: "The specification of the job.",
 						},
 					},
 				},
+				Computed:            true,
+				MarkdownDescription: "The specification of the job.",
+			},
+		},
+	}
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"by_name": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The filtered jobs indexed by name.",
+				NestedObject:        jobObject,
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Only return jobs which are disabled, or enabled if false.",
+				Optional:            true,
+			},
+			"elements": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The list of jobs.",
+				NestedObject:        jobObject,
+			},
+			"identity": schema.StringAttribute{
+				MarkdownDescription: "Only return jobs using this identity, either injected during execution, used by the runner or used by the trigger.",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only return jobs whose name matches this regular expression.",
+				Optional:            true,
 			},
 			"project_id": schema.StringAttribute{
 				MarkdownDescription: "The identifier of the project the jobs are part of.",
 				Required:            true,
 			},
+			"trigger_connector": schema.StringAttribute{
+				MarkdownDescription: "Only return jobs triggered by an event of this connector.",
+				Optional:            true,
+			},
+			"trigger_event": schema.StringAttribute{
+				MarkdownDescription: "Only return jobs triggered by this event formatted as <connector>/<event>.",
+				Optional:            true,
+			},
 		},
 		MarkdownDescription: "Use this data source to retrieve information about existing eventline jobs.",
 	}
@@ -247,6 +349,10 @@ func (d *JobsDataSource) Configure(ctx context.Context, req datasource.Configure
 }
 
 func (d *JobsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addUnconfiguredProviderError(&resp.Diagnostics)
+		return
+	}
 	var data JobsDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
@@ -257,76 +363,185 @@ func (d *JobsDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
 		return
 	}
+	var nameRegex *regexp.Regexp
+	if !data.NameRegex.IsNull() {
+		var err error
+		if nameRegex, err = regexp.Compile(data.NameRegex.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "RegexpCompile", fmt.Sprintf("Unable to compile name regular expression, got error: %s", err))
+			return
+		}
+	}
 	d.client.ProjectId = &id
 	jobs, err := d.client.FetchJobs()
 	if err != nil {
 		resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs, got error: %s", err))
 		return
 	}
-	jobList := make([]JobDataSourceModel, len(jobs))
-	for i, job := range jobs {
-		environment, _ := types.MapValueFrom(ctx, types.StringType, job.Spec.Environment)
-		identities, _ := types.SetValueFrom(ctx, types.StringType, job.Spec.Identities)
-		jobList[i] = JobDataSourceModel{
+	jobList := make([]JobDataSourceModel, 0, len(jobs))
+	jobsByName := make(map[string]JobDataSourceModel)
+	for _, job := range jobs {
+		if !data.Disabled.IsNull() && job.Disabled != data.Disabled.ValueBool() {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(job.Spec.Name) {
+			continue
+		}
+		if !data.Identity.IsNull() && !jobUsesIdentity(job.Spec, data.Identity.ValueString()) {
+			continue
+		}
+		if !data.TriggerConnector.IsNull() && (job.Spec.Trigger == nil || job.Spec.Trigger.Event.Connector != data.TriggerConnector.ValueString()) {
+			continue
+		}
+		if !data.TriggerEvent.IsNull() && (job.Spec.Trigger == nil || job.Spec.Trigger.Event.String() != data.TriggerEvent.ValueString()) {
+			continue
+		}
+		spec, err := newJobSpecDataSourceModel(ctx, job.Spec)
+		if err != nil {
+			resp.Diagnostics.AddError("JobSpecMarshal", fmt.Sprintf("Unable to encode specification of job %s, got error: %s", job.Id, err))
+			return
+		}
+		element := JobDataSourceModel{
 			Disabled: types.BoolValue(job.Disabled),
 			Id:       types.StringValue(job.Id.String()),
-			Spec: JobSpecDataSourceModel{
-				Concurrent:  types.BoolValue(job.Spec.Concurrent),
-				Description: types.StringValue(job.Spec.Description),
-				Environment: environment,
-				Identities:  identities,
-				Name:        types.StringValue(job.Spec.Name),
-				Retention:   types.Int64Value(int64(job.Spec.Retention)),
-			},
+			Spec:     spec,
+		}
+		jobList = append(jobList, element)
+		jobsByName[job.Spec.Name] = element
+	}
+	data.ByName = jobsByName
+	data.Elements = jobList
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func newJobSpecDataSourceModel(ctx context.Context, jobSpec *eventline.JobSpec) (JobSpecDataSourceModel, error) {
+	environment, _ := types.MapValueFrom(ctx, types.StringType, jobSpec.Environment)
+	identities, _ := types.SetValueFrom(ctx, types.StringType, jobSpec.Identities)
+	spec := JobSpecDataSourceModel{
+		Concurrent:  types.BoolValue(jobSpec.Concurrent),
+		Description: types.StringValue(jobSpec.Description),
+		Environment: environment,
+		Identities:  identities,
+		Name:        types.StringValue(jobSpec.Name),
+		Retention:   types.Int64Value(int64(jobSpec.Retention)),
+	}
+	jobParameters := make([]ParameterDataSourceModel, len(jobSpec.Parameters))
+	for j, parameter := range jobSpec.Parameters {
+		values, _ := types.ListValueFrom(ctx, types.StringType, parameter.Values)
+		defaultValue, err := jsonStringValue(parameter.Default)
+		if err != nil {
+			return spec, err
+		}
+		jobParameters[j] = ParameterDataSourceModel{
+			Default:     defaultValue,
+			Description: types.StringValue(parameter.Description),
+			Environment: types.StringValue(parameter.Environment),
+			Name:        types.StringValue(parameter.Name),
+			Type:        types.StringValue(string(parameter.Type)),
+			Values:      values,
+		}
+	}
+	spec.Parameters = jobParameters
+	if jobSpec.Runner != nil {
+		spec.Runner = &RunnerDataSourceModel{
+			Name:       types.StringValue(jobSpec.Runner.Name),
+			Identity:   types.StringValue(jobSpec.Runner.Identity),
+			Parameters: rawJSONStringValue(jobSpec.Runner.RawParameters),
 		}
-		jobParameters := make([]ParameterDataSourceModel, len(job.Spec.Parameters))
-		for j, parameter := range job.Spec.Parameters {
-			values, _ := types.ListValueFrom(ctx, types.StringType, parameter.Values)
-			jobParameters[j] = ParameterDataSourceModel{
-				Description: types.StringValue(parameter.Description),
-				Environment: types.StringValue(parameter.Environment),
-				Name:        types.StringValue(parameter.Name),
-				Type:        types.StringValue(string(parameter.Type)),
-				Values:      values,
+	}
+	jobSteps := make([]StepDataSourceModel, len(jobSpec.Steps))
+	for j, step := range jobSpec.Steps {
+		jobSteps[j] = StepDataSourceModel{
+			Code:      types.StringValue(step.Code),
+			Label:     types.StringValue(step.Label),
+			OnFailure: types.StringValue(string(step.OnFailure)),
+		}
+		if step.Command != nil {
+			arguments, _ := types.ListValueFrom(ctx, types.StringType, step.Command.Arguments)
+			jobSteps[j].Command = &StepCommandDataSourceModel{
+				Arguments: arguments,
+				Name:      types.StringValue(step.Command.Name),
 			}
 		}
-		jobList[i].Spec.Parameters = jobParameters
-		if job.Spec.Runner != nil {
-			jobList[i].Spec.Runner = &RunnerDataSourceModel{
-				Name:     types.StringValue(job.Spec.Runner.Name),
-				Identity: types.StringValue(job.Spec.Runner.Identity),
+		if step.Script != nil {
+			arguments, _ := types.ListValueFrom(ctx, types.StringType, step.Script.Arguments)
+			jobSteps[j].Script = &StepScriptDataSourceModel{
+				Arguments: arguments,
+				Content:   types.StringValue(step.Script.Content),
+				Path:      types.StringValue(step.Script.Path),
 			}
 		}
-		jobSteps := make([]StepDataSourceModel, len(job.Spec.Steps))
-		for j, step := range job.Spec.Steps {
-			jobSteps[j] = StepDataSourceModel{
-				Code:  types.StringValue(step.Code),
-				Label: types.StringValue(step.Label),
+	}
+	spec.Steps = jobSteps
+	if trigger := jobSpec.Trigger; trigger != nil {
+		filters := make([]FilterDataSourceModel, len(trigger.Filters))
+		for j, filter := range trigger.Filters {
+			pointer, err := json.Marshal(filter.Path)
+			if err != nil {
+				return spec, err
 			}
-			if step.Command != nil {
-				arguments, _ := types.ListValueFrom(ctx, types.StringType, step.Command.Arguments)
-				jobSteps[j].Command = &StepCommandDataSourceModel{
-					Arguments: arguments,
-					Name:      types.StringValue(step.Command.Name),
-				}
+			var pathString string
+			if err := json.Unmarshal(pointer, &pathString); err != nil {
+				pathString = string(pointer)
 			}
-			if step.Script != nil {
-				arguments, _ := types.ListValueFrom(ctx, types.StringType, step.Script.Arguments)
-				jobSteps[j].Script = &StepScriptDataSourceModel{
-					Arguments: arguments,
-					Content:   types.StringValue(step.Script.Content),
-					Path:      types.StringValue(step.Script.Path),
-				}
+			isEqualTo, err := jsonStringValue(filter.IsEqualTo)
+			if err != nil {
+				return spec, err
 			}
-		}
-		jobList[i].Spec.Steps = jobSteps
-		if job.Spec.Trigger != nil {
-			jobList[i].Spec.Trigger = &TriggerDataSourceModel{
-				Event:    types.StringValue(job.Spec.Trigger.Event.String()),
-				Identity: types.StringValue(job.Spec.Trigger.Identity),
+			isNotEqualTo, err := jsonStringValue(filter.IsNotEqualTo)
+			if err != nil {
+				return spec, err
 			}
+			filters[j] = FilterDataSourceModel{
+				DoesNotMatch: types.StringValue(filter.DoesNotMatch),
+				IsEqualTo:    isEqualTo,
+				IsNotEqualTo: isNotEqualTo,
+				Matches:      types.StringValue(filter.Matches),
+				Path:         types.StringValue(pathString),
+			}
+		}
+		spec.Trigger = &TriggerDataSourceModel{
+			Connector:  types.StringValue(trigger.Event.Connector),
+			Event:      types.StringValue(trigger.Event.String()),
+			EventName:  types.StringValue(trigger.Event.Event),
+			Filters:    filters,
+			Identity:   types.StringValue(trigger.Identity),
+			Parameters: rawJSONStringValue(trigger.RawParameters),
 		}
 	}
-	data.Elements = jobList
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	return spec, nil
+}
+
+// jobUsesIdentity returns whether a job injects the identity during its
+// execution or uses it for its runner or trigger.
+func jobUsesIdentity(spec *eventline.JobSpec, identity string) bool {
+	for _, name := range spec.Identities {
+		if name == identity {
+			return true
+		}
+	}
+	if spec.Runner != nil && spec.Runner.Identity == identity {
+		return true
+	}
+	return spec.Trigger != nil && spec.Trigger.Identity == identity
+}
+
+// jsonStringValue encodes a decoded json value, a nil value being null.
+func jsonStringValue(value interface{}) (types.String, error) {
+	if value == nil {
+		return types.StringNull(), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(string(data)), nil
+}
+
+// rawJSONStringValue returns raw json data as a string, missing data being
+// null.
+func rawJSONStringValue(data json.RawMessage) types.String {
+	if len(data) == 0 || string(data) == "null" {
+		return types.StringNull()
+	}
+	return types.StringValue(string(data))
 }
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJobSpec = `{
+  "name": "deploy",
+  "description": "Deploy the application",
+  "trigger": {
+    "event": "github/push",
+    "parameters": {"organization": "example", "repository": "app"},
+    "identity": "github",
+    "filters": [
+      {"path": "/branch", "is_equal_to": "main"},
+      {"path": "/ref", "is_not_equal_to": {"type": "tag"}},
+      {"path": "/commit/message", "matches": "^deploy", "does_not_match": "wip"}
+    ]
+  },
+  "parameters": [
+    {"name": "count", "type": "integer", "default": 3, "environment": "COUNT"},
+    {"name": "ratio", "type": "number", "default": 0.5},
+    {"name": "flag", "type": "boolean", "default": true, "description": "A flag"},
+    {"name": "env", "type": "string", "values": ["staging", "production"], "default": "staging"}
+  ],
+  "runner": {
+    "name": "ssh",
+    "parameters": {"host": "example.com", "port": 22},
+    "identity": "ssh-key"
+  },
+  "concurrent": true,
+  "retention": 30,
+  "identities": ["aws"],
+  "environment": {"REGION": "eu-west-1"},
+  "steps": [
+    {"label": "Code", "code": "echo hello", "on_failure": "continue"},
+    {"label": "Command", "command": {"name": "make", "arguments": ["deploy"]}},
+    {"label": "Script", "script": {"path": "deploy.sh", "arguments": ["-v"], "content": "#!/bin/sh\n"}}
+  ]
+}`
+
+func TestJobSpecDataSourceModelRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	jobSpec, err := DecodeJobSpec(testJobSpec)
+	if !assert.NoError(err) {
+		return
+	}
+	spec, err := newJobSpecDataSourceModel(ctx, jobSpec)
+	if !assert.NoError(err) {
+		return
+	}
+
+	var expected map[string]interface{}
+	if !assert.NoError(json.Unmarshal([]byte(testJobSpec), &expected)) {
+		return
+	}
+	assert.Equal(expected, encodeJobSpecDataSourceModel(t, ctx, spec))
+	assert.Equal("github/push", spec.Trigger.Event.ValueString())
+}
+
+// encodeJobSpecDataSourceModel rebuilds the json form of a job specification
+// from the data source model, omitting empty values like eventline does.
+func encodeJobSpecDataSourceModel(t *testing.T, ctx context.Context, spec JobSpecDataSourceModel) map[string]interface{} {
+	obj := make(map[string]interface{})
+	setString(obj, "name", spec.Name)
+	setString(obj, "description", spec.Description)
+	if spec.Concurrent.ValueBool() {
+		obj["concurrent"] = true
+	}
+	if retention := spec.Retention.ValueInt64(); retention != 0 {
+		obj["retention"] = float64(retention)
+	}
+	if identities := stringSlice(t, ctx, spec.Identities); len(identities) > 0 {
+		obj["identities"] = identities
+	}
+	if len(spec.Environment.Elements()) > 0 {
+		var environment map[string]string
+		spec.Environment.ElementsAs(ctx, &environment, false)
+		m := make(map[string]interface{})
+		for k, v := range environment {
+			m[k] = v
+		}
+		obj["environment"] = m
+	}
+	if len(spec.Parameters) > 0 {
+		parameters := make([]interface{}, len(spec.Parameters))
+		for i, parameter := range spec.Parameters {
+			p := make(map[string]interface{})
+			setString(p, "name", parameter.Name)
+			setString(p, "type", parameter.Type)
+			setString(p, "description", parameter.Description)
+			setString(p, "environment", parameter.Environment)
+			setJSON(t, p, "default", parameter.Default)
+			if values := stringSlice(t, ctx, parameter.Values); len(values) > 0 {
+				p["values"] = values
+			}
+			parameters[i] = p
+		}
+		obj["parameters"] = parameters
+	}
+	if spec.Runner != nil {
+		runner := make(map[string]interface{})
+		setString(runner, "name", spec.Runner.Name)
+		setString(runner, "identity", spec.Runner.Identity)
+		setJSON(t, runner, "parameters", spec.Runner.Parameters)
+		obj["runner"] = runner
+	}
+	if spec.Trigger != nil {
+		trigger := make(map[string]interface{})
+		trigger["event"] = spec.Trigger.Connector.ValueString() + "/" + spec.Trigger.EventName.ValueString()
+		setString(trigger, "identity", spec.Trigger.Identity)
+		setJSON(t, trigger, "parameters", spec.Trigger.Parameters)
+		if len(spec.Trigger.Filters) > 0 {
+			filters := make([]interface{}, len(spec.Trigger.Filters))
+			for i, filter := range spec.Trigger.Filters {
+				f := make(map[string]interface{})
+				setString(f, "path", filter.Path)
+				setJSON(t, f, "is_equal_to", filter.IsEqualTo)
+				setJSON(t, f, "is_not_equal_to", filter.IsNotEqualTo)
+				setString(f, "matches", filter.Matches)
+				setString(f, "does_not_match", filter.DoesNotMatch)
+				filters[i] = f
+			}
+			trigger["filters"] = filters
+		}
+		obj["trigger"] = trigger
+	}
+	steps := make([]interface{}, len(spec.Steps))
+	for i, step := range spec.Steps {
+		s := make(map[string]interface{})
+		setString(s, "label", step.Label)
+		setString(s, "code", step.Code)
+		setString(s, "on_failure", step.OnFailure)
+		if step.Command != nil {
+			command := make(map[string]interface{})
+			setString(command, "name", step.Command.Name)
+			if arguments := stringSlice(t, ctx, step.Command.Arguments); len(arguments) > 0 {
+				command["arguments"] = arguments
+			}
+			s["command"] = command
+		}
+		if step.Script != nil {
+			script := make(map[string]interface{})
+			setString(script, "path", step.Script.Path)
+			setString(script, "content", step.Script.Content)
+			if arguments := stringSlice(t, ctx, step.Script.Arguments); len(arguments) > 0 {
+				script["arguments"] = arguments
+			}
+			s["script"] = script
+		}
+		steps[i] = s
+	}
+	obj["steps"] = steps
+	return obj
+}
+
+func setString(obj map[string]interface{}, key string, value types.String) {
+	if value.ValueString() != "" {
+		obj[key] = value.ValueString()
+	}
+}
+
+func setJSON(t *testing.T, obj map[string]interface{}, key string, value types.String) {
+	if value.IsNull() {
+		return
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(value.ValueString()), &v); err != nil {
+		t.Fatalf("invalid json for %s: %v", key, err)
+	}
+	obj[key] = v
+}
+
+func stringSlice(t *testing.T, ctx context.Context, value interface {
+	ElementsAs(context.Context, interface{}, bool) diag.Diagnostics
+}) []interface{} {
+	var elements []string
+	if diags := value.ElementsAs(ctx, &elements, false); diags.HasError() {
+		t.Fatalf("invalid elements: %v", diags)
+	}
+	if len(elements) == 0 {
+		return nil
+	}
+	slice := make([]interface{}, len(elements))
+	for i, element := range elements {
+		slice[i] = element
+	}
+	return slice
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// JSONType is a string type holding a json document. Its values are compared
+// semantically, so that whitespace and key order differences between the
+// configuration and what eventline returns do not produce a plan diff.
+type JSONType struct {
+	basetypes.StringType
+}
+
+var _ basetypes.StringTypable = JSONType{} // Ensure provider defined types fully satisfy framework interfaces
+
+func (t JSONType) Equal(o attr.Type) bool {
+	other, ok := o.(JSONType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t JSONType) String() string {
+	return "provider.JSONType"
+}
+
+func (t JSONType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return JSONValue{StringValue: in}, nil
+}
+
+func (t JSONType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return JSONValue{StringValue: stringValue}, nil
+}
+
+func (t JSONType) ValueType(ctx context.Context) attr.Value {
+	return JSONValue{}
+}
+
+type JSONValue struct {
+	basetypes.StringValue
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = JSONValue{} // Ensure provider defined types fully satisfy framework interfaces
+var _ xattr.ValidateableAttribute = JSONValue{}                // Ensure provider defined types fully satisfy framework interfaces
+
+func NewJSONUnknown() JSONValue {
+	return JSONValue{StringValue: basetypes.NewStringUnknown()}
+}
+
+func NewJSONValue(value string) JSONValue {
+	return JSONValue{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v JSONValue) Equal(o attr.Value) bool {
+	other, ok := o.(JSONValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v JSONValue) Type(ctx context.Context) attr.Type {
+	return JSONType{}
+}
+
+// StringSemanticEquals returns true if both values hold the same json
+// document, regardless of formatting and key order.
+func (v JSONValue) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(JSONValue)
+	if !ok {
+		diags.AddError("SemanticEquality", fmt.Sprintf("Expected value type %T but got value type %T", v, newValuable))
+		return false, diags
+	}
+	equal, err := JSONRawDataEqual(json.RawMessage(v.ValueString()), json.RawMessage(newValue.ValueString()))
+	if err != nil {
+		return false, nil // invalid json is reported by ValidateAttribute
+	}
+	return equal, nil
+}
+
+func (v JSONValue) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() || v.IsUnknown() {
+		return
+	}
+	if !json.Valid([]byte(v.ValueString())) {
+		resp.Diagnostics.AddAttributeError(req.Path, "InvalidJSON", "The value must be a valid json document")
+	}
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONValue(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	value := NewJSONValue(`{"a": 1, "b": [1, 2]}`)
+	equal, diags := value.StringSemanticEquals(ctx, NewJSONValue(`{"b":[1,2],"a":1}`))
+	assert.False(diags.HasError())
+	assert.True(equal)
+
+	equal, diags = value.StringSemanticEquals(ctx, NewJSONValue(`{"a": 1, "b": [2, 1]}`))
+	assert.False(diags.HasError())
+	assert.False(equal)
+
+	equal, diags = value.StringSemanticEquals(ctx, NewJSONValue(`{"a": 1`))
+	assert.False(diags.HasError())
+	assert.False(equal)
+
+	var resp xattr.ValidateAttributeResponse
+	NewJSONValue(`{"a": 1`).ValidateAttribute(ctx, xattr.ValidateAttributeRequest{Path: path.Root("data")}, &resp)
+	assert.True(resp.Diagnostics.HasError())
+
+	resp = xattr.ValidateAttributeResponse{}
+	NewJSONUnknown().ValidateAttribute(ctx, xattr.ValidateAttributeRequest{Path: path.Root("data")}, &resp)
+	assert.False(resp.Diagnostics.HasError())
+}
@@ -5,8 +5,12 @@ import (
 	"fmt"
 
 	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -14,7 +18,8 @@ type ProjectDataSource struct {
 	client *evcli.Client
 }
 
-var _ datasource.DataSource = &ProjectDataSource{} // Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &ProjectDataSource{}                     // Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSourceWithConfigValidators = &ProjectDataSource{} // Ensure provider defined types fully satisfy framework interfaces
 func NewProjectDataSource() datasource.DataSource {
 	return &ProjectDataSource{}
 }
@@ -28,14 +33,25 @@ func (d *ProjectDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "The identifier of the project.",
+				MarkdownDescription: "The identifier of the project. Exactly one of `id` or `name` must be set.",
+				Optional:            true,
 			},
 			"name": schema.StringAttribute{
-				MarkdownDescription: "The name of the project.",
-				Required:            true,
+				Computed:            true,
+				MarkdownDescription: "The name of the project. Exactly one of `id` or `name` must be set.",
+				Optional:            true,
 			},
 		},
-		MarkdownDescription: "Use this data source to retrieve information about an existing eventline project from its name.",
+		MarkdownDescription: "Use this data source to retrieve information about an existing eventline project from its identifier or its name.",
+	}
+}
+
+func (d *ProjectDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(
+			path.MatchRoot("id"),
+			path.MatchRoot("name"),
+		),
 	}
 }
 
@@ -44,13 +60,28 @@ func (d *ProjectDataSource) Configure(ctx context.Context, req datasource.Config
 }
 
 func (d *ProjectDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addUnconfiguredProviderError(&resp.Diagnostics)
+		return
+	}
 	var data ProjectDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	project, err := d.client.FetchProjectByName(data.Name.ValueString())
-	if err != nil {
+	var project *eventline.Project
+	var err error
+	if !data.Id.IsNull() {
+		var id ksuid.KSUID
+		if err := id.Parse(data.Id.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("id"), "KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+			return
+		}
+		if project, err = d.client.FetchProjectById(id); err != nil {
+			resp.Diagnostics.AddError("FetchProjectById", fmt.Sprintf("Unable to fetch project, got error: %s", err))
+			return
+		}
+	} else if project, err = d.client.FetchProjectByName(data.Name.ValueString()); err != nil {
 		resp.Diagnostics.AddError("FetchProjectByName", fmt.Sprintf("Unable to fetch project, got error: %s", err))
 		return
 	}
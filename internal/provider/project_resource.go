@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -27,8 +28,11 @@ func NewProjectResource() resource.Resource {
 }
 
 type ProjectResourceModel struct {
-	Id   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
+	AdoptExisting      types.Bool   `tfsdk:"adopt_existing"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	ForceDestroy       types.Bool   `tfsdk:"force_destroy"`
+	Id                 types.String `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
 }
 
 func (r *ProjectResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -38,6 +42,24 @@ func (r *ProjectResource) Metadata(ctx context.Context, req resource.MetadataReq
 func (r *ProjectResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"adopt_existing": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				MarkdownDescription: "Whether to take ownership of an existing project with the same name instead of failing when creating the project. Defaults to `false`.",
+				Optional:            true,
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether terraform is prevented from destroying the project or not. It must be set to `false` and applied before the project can be destroyed. Defaults to `true`.",
+				Optional:            true,
+			},
+			"force_destroy": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				MarkdownDescription: "Whether the project can be destroyed while it still has jobs or identities, which are destroyed along with it and their execution history. Defaults to `false`.",
+				Optional:            true,
+			},
 			"id": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "Project Id",
@@ -66,14 +88,26 @@ func (r *ProjectResource) Create(ctx context.Context, req resource.CreateRequest
 	}
 	project := eventline.Project{Name: data.Name.ValueString()}
 	if err := r.client.CreateProject(&project); err != nil {
-		resp.Diagnostics.AddError("CreateProject", fmt.Sprintf("Unable to create project, got error: %s\nTry importing the resource instead?", err))
-		return
+		var e *evcli.APIError
+		if !data.AdoptExisting.ValueBool() || !errors.As(err, &e) || e.Code != "duplicate_project_name" {
+			resp.Diagnostics.AddError("CreateProject", fmt.Sprintf("Unable to create project, got error: %s\nTry importing the resource or setting adopt_existing instead?", err))
+			return
+		}
+		existing, err := r.client.FetchProjectByName(data.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("FetchProjectByName", fmt.Sprintf("Unable to fetch existing project by name, got error: %s", err))
+			return
+		}
+		project = *existing // The name is the only attribute of a project, there is nothing to update
 	}
 	data.Id = types.StringValue(project.Id.String())
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *ProjectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		return // The provider configuration is not known yet, the state is kept as is
+	}
 	var data *ProjectResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
@@ -94,6 +128,15 @@ func (r *ProjectResource) Read(ctx context.Context, req resource.ReadRequest, re
 		resp.Diagnostics.AddError("FetchProjectById", fmt.Sprintf("Unable to fetch project by id, got error: %s", err))
 		return
 	}
+	if data.AdoptExisting.IsNull() {
+		data.AdoptExisting = types.BoolValue(false) // The project was imported
+	}
+	if data.DeletionProtection.IsNull() {
+		data.DeletionProtection = types.BoolValue(true)
+	}
+	if data.ForceDestroy.IsNull() {
+		data.ForceDestroy = types.BoolValue(false)
+	}
 	data.Id = types.StringValue(project.Id.String())
 	data.Name = types.StringValue(project.Name)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -130,6 +173,35 @@ func (r *ProjectResource) Delete(ctx context.Context, req resource.DeleteRequest
 		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
 		return
 	}
+	if data.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddError("DeletionProtection", fmt.Sprintf("Project %s is protected against deletion, set deletion_protection to false and apply before destroying it", data.Name.ValueString()))
+		return
+	}
+	if !data.ForceDestroy.ValueBool() {
+		r.client.ProjectId = &id
+		jobs, err := r.client.FetchJobs()
+		if err != nil {
+			var e *evcli.APIError
+			if errors.As(err, &e) && e.Code == "unknown_project" {
+				return // the project does not exist, that is what we want
+			}
+			resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs of project %s, got error: %s", data.Name.ValueString(), err))
+			return
+		}
+		identities, err := r.client.FetchIdentities()
+		if err != nil {
+			var e *evcli.APIError
+			if errors.As(err, &e) && e.Code == "unknown_project" {
+				return // the project was deleted in the meantime, that is what we want
+			}
+			resp.Diagnostics.AddError("FetchIdentities", fmt.Sprintf("Unable to fetch identities of project %s, got error: %s", data.Name.ValueString(), err))
+			return
+		}
+		if len(jobs) > 0 || len(identities) > 0 {
+			resp.Diagnostics.AddError("ProjectNotEmpty", fmt.Sprintf("Project %s still has %d jobs and %d identities, set force_destroy to true and apply before destroying it along with its jobs, identities and execution history", data.Name.ValueString(), len(jobs), len(identities)))
+			return
+		}
+	}
 	if err := r.client.DeleteProject(id); err != nil {
 		var e *evcli.APIError
 		if errors.As(err, &e) && e.Code == "unknown_project" {
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProjectTestModel(name string, deletionProtection, forceDestroy types.Bool) *ProjectResourceModel {
+	return &ProjectResourceModel{
+		AdoptExisting:      types.BoolNull(),
+		DeletionProtection: deletionProtection,
+		ForceDestroy:       forceDestroy,
+		Id:                 types.StringNull(),
+		Name:               types.StringValue(name),
+	}
+}
+
+// projectChange returns the prior, config and proposed values of a change of
+// project, computed attributes being kept from the prior model when not
+// configured.
+func projectChange(prior, config *ProjectResourceModel) (interface{}, interface{}, interface{}) {
+	if prior == nil {
+		return nil, config, config
+	}
+	if config == nil {
+		return prior, nil, nil
+	}
+	proposed := *config
+	proposed.Id = prior.Id
+	if proposed.AdoptExisting.IsNull() {
+		proposed.AdoptExisting = prior.AdoptExisting
+	}
+	if proposed.DeletionProtection.IsNull() {
+		proposed.DeletionProtection = prior.DeletionProtection
+	}
+	if proposed.ForceDestroy.IsNull() {
+		proposed.ForceDestroy = prior.ForceDestroy
+	}
+	return prior, config, &proposed
+}
+
+func TestProjectResourceDeletionProtection(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewProjectResource())
+
+	var project *ProjectResourceModel
+	prior, config, proposed := projectChange(nil, newProjectTestModel("test", types.BoolNull(), types.BoolNull()))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &project))
+	assert.Equal([]string{"POST /projects"}, f.takeRequests())
+	assert.True(project.DeletionProtection.ValueBool())
+	assert.False(project.ForceDestroy.ValueBool())
+
+	// Protected projects cannot be destroyed
+	prior, config, proposed = projectChange(project, nil)
+	diags := r.apply(t, prior, config, proposed, nil)
+	assert.Equal([]string{"DeletionProtection"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	assert.Empty(f.takeRequests())
+
+	prior, config, proposed = projectChange(project, newProjectTestModel("test", types.BoolValue(false), types.BoolNull()))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &project))
+	assert.Equal([]string{"PUT /projects/id/" + project.Id.ValueString()}, f.takeRequests())
+	assert.False(project.DeletionProtection.ValueBool())
+
+	// Projects with jobs or identities require force_destroy
+	f.addJob(project.Id.ValueString(), &eventline.JobSpec{Name: "job"})
+	prior, config, proposed = projectChange(project, nil)
+	diags = r.apply(t, prior, config, proposed, nil)
+	assert.Equal([]string{"ProjectNotEmpty"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	assert.Equal([]string{"GET /jobs", "GET /identities"}, f.takeRequests())
+
+	prior, config, proposed = projectChange(project, newProjectTestModel("test", types.BoolValue(false), types.BoolValue(true)))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &project))
+	f.takeRequests()
+	prior, config, proposed = projectChange(project, nil)
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, nil))
+	assert.Equal([]string{"DELETE /projects/id/" + project.Id.ValueString()}, f.takeRequests())
+	assert.Empty(f.projects)
+
+	// Empty projects are destroyed without force_destroy
+	prior, config, proposed = projectChange(nil, newProjectTestModel("empty", types.BoolValue(false), types.BoolNull()))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &project))
+	f.takeRequests()
+	prior, config, proposed = projectChange(project, nil)
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, nil))
+	assert.Equal([]string{"GET /jobs", "GET /identities", "DELETE /projects/id/" + project.Id.ValueString()}, f.takeRequests())
+}
+
+func TestProjectResourceAdoptExisting(t *testing.T) {
+	assert := assert.New(t)
+	f, server := newFakeEventline(t)
+	r := newTestResource(server, NewProjectResource())
+
+	var existing *ProjectResourceModel
+	prior, config, proposed := projectChange(nil, newProjectTestModel("test", types.BoolNull(), types.BoolNull()))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &existing))
+	f.takeRequests()
+
+	model := newProjectTestModel("test", types.BoolValue(false), types.BoolNull())
+	prior, config, proposed = projectChange(nil, model)
+	diags := r.apply(t, prior, config, proposed, nil)
+	assert.Equal([]string{"CreateProject"}, diagnosticSummaries(diags, tfprotov6.DiagnosticSeverityError))
+	f.takeRequests()
+
+	model.AdoptExisting = types.BoolValue(true)
+	var project *ProjectResourceModel
+	prior, config, proposed = projectChange(nil, model)
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, &project))
+	assert.Equal([]string{"POST /projects", "GET /projects/name/test"}, f.takeRequests())
+	assert.Equal(existing.Id, project.Id)
+	assert.False(project.DeletionProtection.ValueBool())
+	assert.Len(f.projects, 1)
+}
@@ -3,10 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -20,7 +23,17 @@ func NewProjectsDataSource() datasource.DataSource {
 }
 
 type ProjectsDataSourceModel struct {
-	Elements []ProjectDataSourceModel `tfsdk:"elements"`
+	ByName        map[string]ProjectElementDataSourceModel `tfsdk:"by_name"`
+	Elements      []ProjectElementDataSourceModel          `tfsdk:"elements"`
+	IncludeCounts types.Bool                               `tfsdk:"include_counts"`
+	NamePrefix    types.String                             `tfsdk:"name_prefix"`
+	NameRegex     types.String                             `tfsdk:"name_regex"`
+}
+type ProjectElementDataSourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	IdentityCount types.Int64  `tfsdk:"identity_count"`
+	JobCount      types.Int64  `tfsdk:"job_count"`
+	Name          types.String `tfsdk:"name"`
 }
 type ProjectDataSourceModel struct {
 	Id   types.String `tfsdk:"id"`
@@ -32,23 +45,49 @@ func (d *ProjectsDataSource) Metadata(ctx context.Context, req datasource.Metada
 }
 
 func (d *ProjectsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	projectObject := schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The identifier of the project.",
+			},
+			"identity_count": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "The number of identities of the project, only set when `include_counts` is true.",
+			},
+			"job_count": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "The number of jobs of the project, only set when `include_counts` is true.",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The name of the project.",
+			},
+		},
+	}
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"by_name": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The filtered projects indexed by name.",
+				NestedObject:        projectObject,
+			},
 			"elements": schema.ListNestedAttribute{
 				Computed:            true,
 				MarkdownDescription: "The list of projects.",
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"id": schema.StringAttribute{
-							Computed:            true,
-							MarkdownDescription: "The identifier of the project.",
-						},
-						"name": schema.StringAttribute{
-							Computed:            true,
-							MarkdownDescription: "The name of the project.",
-						},
-					},
-				},
+				NestedObject:        projectObject,
+			},
+			"include_counts": schema.BoolAttribute{
+				MarkdownDescription: "Whether to count the jobs and identities of each project or not, which requires two more api calls per project. Defaults to false.",
+				Optional:            true,
+			},
+			"name_prefix": schema.StringAttribute{
+				MarkdownDescription: "Only return projects whose name starts with this prefix.",
+				Optional:            true,
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only return projects whose name matches this regular expression.",
+				Optional:            true,
 			},
 		},
 		MarkdownDescription: "Use this data source to retrieve information about existing eventline projects.",
@@ -60,20 +99,63 @@ func (d *ProjectsDataSource) Configure(ctx context.Context, req datasource.Confi
 }
 
 func (d *ProjectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		addUnconfiguredProviderError(&resp.Diagnostics)
+		return
+	}
 	var data ProjectsDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	var nameRegex *regexp.Regexp
+	if !data.NameRegex.IsNull() {
+		var err error
+		if nameRegex, err = regexp.Compile(data.NameRegex.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("name_regex"), "RegexpCompile", fmt.Sprintf("Unable to compile name regular expression, got error: %s", err))
+			return
+		}
+	}
 	projects, err := d.client.FetchProjects()
 	if err != nil {
 		resp.Diagnostics.AddError("FetchProjects", fmt.Sprintf("Unable to fetch projects, got error: %s", err))
 		return
 	}
-	projectList := make([]ProjectDataSourceModel, len(projects))
-	for i, project := range projects {
-		projectList[i] = ProjectDataSourceModel{Id: types.StringValue(project.Id.String()), Name: types.StringValue(project.Name)}
+	projectList := make([]ProjectElementDataSourceModel, 0, len(projects))
+	projectsByName := make(map[string]ProjectElementDataSourceModel)
+	for _, project := range projects {
+		if !data.NamePrefix.IsNull() && !strings.HasPrefix(project.Name, data.NamePrefix.ValueString()) {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(project.Name) {
+			continue
+		}
+		element := ProjectElementDataSourceModel{
+			Id:            types.StringValue(project.Id.String()),
+			IdentityCount: types.Int64Null(),
+			JobCount:      types.Int64Null(),
+			Name:          types.StringValue(project.Name),
+		}
+		if data.IncludeCounts.ValueBool() {
+			id := project.Id
+			d.client.ProjectId = &id
+			jobs, err := d.client.FetchJobs()
+			if err != nil {
+				resp.Diagnostics.AddError("FetchJobs", fmt.Sprintf("Unable to fetch jobs of project %s, got error: %s", project.Name, err))
+				return
+			}
+			identities, err := d.client.FetchIdentities()
+			if err != nil {
+				resp.Diagnostics.AddError("FetchIdentities", fmt.Sprintf("Unable to fetch identities of project %s, got error: %s", project.Name, err))
+				return
+			}
+			element.IdentityCount = types.Int64Value(int64(len(identities)))
+			element.JobCount = types.Int64Value(int64(len(jobs)))
+		}
+		projectList = append(projectList, element)
+		projectsByName[project.Name] = element
 	}
+	data.ByName = projectsByName
 	data.Elements = projectList
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
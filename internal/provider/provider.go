@@ -2,10 +2,24 @@ package provider
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
 
 	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/eventline"
+	"github.com/hashicorp/terraform-plugin-framework-validators/providervalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -16,7 +30,9 @@ type Provider struct {
 	version string
 }
 
-var _ provider.Provider = &Provider{} // Ensure provider defined types fully satisfy framework interfaces.
+var _ provider.Provider = &Provider{}                     // Ensure provider defined types fully satisfy framework interfaces.
+var _ provider.ProviderWithActions = &Provider{}          // Ensure provider defined types fully satisfy framework interfaces.
+var _ provider.ProviderWithConfigValidators = &Provider{} // Ensure provider defined types fully satisfy framework interfaces.
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &Provider{
@@ -26,8 +42,12 @@ func New(version string) func() provider.Provider {
 }
 
 type ProviderModel struct {
-	ApiKey   types.String `tfsdk:"api_key"`
-	Endpoint types.String `tfsdk:"endpoint"`
+	ApiKey                    types.String `tfsdk:"api_key"`
+	ApiKeyCommand             types.List   `tfsdk:"api_key_command"`
+	ApiKeyFile                types.String `tfsdk:"api_key_file"`
+	Endpoint                  types.String `tfsdk:"endpoint"`
+	Headers                   types.Map    `tfsdk:"headers"`
+	SkipCredentialsValidation types.Bool   `tfsdk:"skip_credentials_validation"`
 }
 
 func (p *Provider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -39,14 +59,33 @@ func (p *Provider) Schema(ctx context.Context, req provider.SchemaRequest, resp
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"api_key": schema.StringAttribute{
-				MarkdownDescription: "Eventline's api key",
-				Required:            true,
+				MarkdownDescription: "Eventline's api key. Exactly one of `api_key`, `api_key_command` or `api_key_file` must be set.",
+				Optional:            true,
 				Sensitive:           true,
 			},
+			"api_key_command": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "A command and its arguments whose standard output is Eventline's api key. The command is run once when configuring the provider, without a shell, and surrounding whitespace is trimmed from its output.",
+				Optional:            true,
+			},
+			"api_key_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file containing Eventline's api key. The file is read once when configuring the provider and surrounding whitespace is trimmed from its contents.",
+				Optional:            true,
+			},
 			"endpoint": schema.StringAttribute{
 				MarkdownDescription: "Eventline's HTTP endpoint",
 				Required:            true,
 			},
+			"headers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional HTTP headers to send with every request, for example to authenticate with a reverse proxy in front of Eventline.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				MarkdownDescription: "Skip the authenticated api call used to check the endpoint and api key when configuring the provider. Useful for offline validation. Defaults to `false`.",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -57,27 +96,150 @@ func (p *Provider) Configure(ctx context.Context, req provider.ConfigureRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	config := evcli.APIConfig{Endpoint: data.Endpoint.ValueString(), Key: data.ApiKey.ValueString()}
+	if !data.Endpoint.IsUnknown() {
+		endpoint, err := url.Parse(data.Endpoint.ValueString())
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			resp.Diagnostics.AddAttributeError(path.Root("endpoint"), "invalid endpoint", fmt.Sprintf("The eventline endpoint must be an absolute http or https url, got: %q", data.Endpoint.ValueString()))
+			return
+		}
+	}
+	if data.Endpoint.IsUnknown() || data.ApiKey.IsUnknown() || data.ApiKeyCommand.IsUnknown() || data.ApiKeyFile.IsUnknown() || data.Headers.IsUnknown() {
+		// The api cannot be called until the configuration is known, which
+		// usually happens when applying. Terraform defers the resources and
+		// data sources when it supports it, otherwise no client is handed
+		// out and they skip or refuse their api calls.
+		if req.ClientCapabilities.DeferralAllowed {
+			resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+		}
+		return
+	}
+	apiKey, err := readApiKey(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError("api key", fmt.Sprintf("Unable to read eventline api key, got error: %s", err))
+		return
+	}
+	config := evcli.APIConfig{Endpoint: data.Endpoint.ValueString(), Key: apiKey}
 	client, err := evcli.NewClient(&config)
 	if err != nil {
 		resp.Diagnostics.AddError("new api client", fmt.Sprintf("Unable to instantiate eventline api client, got error: %s", err))
 		return
 	}
+	resp.Diagnostics.Append(data.Headers.ElementsAs(ctx, &client.Headers, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	client.UserAgent = fmt.Sprintf("terraform-provider-eventline/%s terraform/%s", p.version, req.TerraformVersion)
+	if !data.SkipCredentialsValidation.ValueBool() {
+		if _, err := client.FetchProjectPage(eventline.Cursor{Size: 1}); err != nil {
+			resp.Diagnostics.AddError("credentials validation", describeConnectivityError(data.Endpoint.ValueString(), err))
+			return
+		}
+	}
 
+	resp.ActionData = client
 	resp.DataSourceData = client
 	resp.ResourceData = client
 }
 
+func (p *Provider) ConfigValidators(ctx context.Context) []provider.ConfigValidator {
+	return []provider.ConfigValidator{
+		providervalidator.ExactlyOneOf(
+			path.MatchRoot("api_key"),
+			path.MatchRoot("api_key_command"),
+			path.MatchRoot("api_key_file"),
+		),
+	}
+}
+
+func readApiKey(ctx context.Context, data *ProviderModel) (string, error) {
+	var key string
+	switch {
+	case !data.ApiKeyFile.IsNull():
+		contents, err := os.ReadFile(data.ApiKeyFile.ValueString())
+		if err != nil {
+			return "", fmt.Errorf("cannot read api key file: %w", err)
+		}
+		key = strings.TrimSpace(string(contents))
+		if key == "" {
+			return "", fmt.Errorf("api key file %q is empty", data.ApiKeyFile.ValueString())
+		}
+	case !data.ApiKeyCommand.IsNull():
+		var args []string
+		if diags := data.ApiKeyCommand.ElementsAs(ctx, &args, false); diags.HasError() {
+			return "", fmt.Errorf("invalid api key command arguments")
+		}
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("api key command must not be empty")
+		}
+		var stderr strings.Builder
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("cannot run api key command %q: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		key = strings.TrimSpace(string(output))
+		if key == "" {
+			return "", fmt.Errorf("api key command %q produced an empty output", args[0])
+		}
+	default:
+		key = data.ApiKey.ValueString()
+	}
+	return key, nil
+}
+
+// addUnconfiguredProviderError reports a data source read while the provider
+// configuration is not known yet.
+func addUnconfiguredProviderError(diags *diag.Diagnostics) {
+	diags.AddError("UnconfiguredProvider", "Unable to call the eventline api because the endpoint, api key or headers of the provider are not known yet, they probably depend on resources which are not applied yet")
+}
+
+func describeConnectivityError(endpoint string, err error) string {
+	var apiErr *evcli.APIError
+	var certErr *tls.CertificateVerificationError
+	var dnsErr *net.DNSError
+	var hostnameErr x509.HostnameError
+	var recordHeaderErr tls.RecordHeaderError
+	var responseErr *evcli.ResponseError
+	var syntaxErr *json.SyntaxError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("Unable to resolve the host of the eventline endpoint %q, got error: %s", endpoint, err)
+	case errors.As(err, &certErr), errors.As(err, &hostnameErr), errors.As(err, &unknownAuthorityErr):
+		return fmt.Sprintf("Unable to verify the TLS certificate of the eventline endpoint %q, got error: %s", endpoint, err)
+	case errors.As(err, &recordHeaderErr):
+		return fmt.Sprintf("Unable to establish a TLS connection with the eventline endpoint %q, check that it does not use https on a plain http port, got error: %s", endpoint, err)
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == 401 || apiErr.StatusCode == 403):
+		return fmt.Sprintf("The eventline api key was rejected by %q, check that it is valid and has not been deleted, got error: %s", endpoint, err)
+	case errors.As(err, &responseErr) && (responseErr.StatusCode == 401 || responseErr.StatusCode == 403):
+		return fmt.Sprintf("The request was rejected by %q with status %d before reaching eventline, check your proxy configuration, got error: %s", endpoint, responseErr.StatusCode, err)
+	case errors.As(err, &responseErr), errors.As(err, &syntaxErr):
+		return fmt.Sprintf("The endpoint %q did not reply like an eventline api, check that it points to the eventline api port and not the web interface, got error: %s", endpoint, err)
+	}
+	return fmt.Sprintf("Unable to reach the eventline endpoint %q, got error: %s", endpoint, err)
+}
+
+func (p *Provider) Actions(ctx context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewReplayEventAction,
+	}
+}
+
 func (p *Provider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewIdentityResource,
+		NewJobSetResource,
+		NewJobStateResource,
 		NewProjectResource,
 	}
 }
 
 func (p *Provider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
+		NewEventsDataSource,
 		NewIdentitiesDataSource,
+		NewIdentityTypesDataSource,
 		NewJobsDataSource,
 		NewProjectDataSource,
 		NewProjectsDataSource,
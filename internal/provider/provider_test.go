@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadApiKey(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("  secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte(" \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	command := func(args ...string) types.List {
+		list, _ := types.ListValueFrom(ctx, types.StringType, args)
+		return list
+	}
+
+	testCases := []struct {
+		name    string
+		command types.List
+		file    types.String
+		key     string
+		err     string
+	}{
+		{"file", types.ListNull(types.StringType), types.StringValue(keyFile), "secret", ""},
+		{"missing file", types.ListNull(types.StringType), types.StringValue(filepath.Join(dir, "missing")), "", "cannot read api key file"},
+		{"empty file", types.ListNull(types.StringType), types.StringValue(emptyFile), "", "is empty"},
+		{"command", command("sh", "-c", "echo ' secret '"), types.StringNull(), "secret", ""},
+		{"failing command", command("sh", "-c", "echo denied >&2; exit 3"), types.StringNull(), "", "exit status 3: denied"},
+		{"empty command", command("sh", "-c", "true"), types.StringNull(), "", "empty output"},
+	}
+	for _, tc := range testCases {
+		key, err := readApiKey(ctx, &ProviderModel{
+			ApiKey:        types.StringNull(),
+			ApiKeyCommand: tc.command,
+			ApiKeyFile:    tc.file,
+		})
+		if tc.err == "" {
+			assert.NoError(err, tc.name)
+		} else if assert.Error(err, tc.name) {
+			assert.Contains(err.Error(), tc.err, tc.name)
+		}
+		assert.Equal(tc.key, key, tc.name)
+	}
+}
+
+func TestProviderConfigureUnknownApiKey(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(401)
+	}))
+	t.Cleanup(server.Close)
+
+	p := New("test")()
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	config := testValue(t, tfsdk.State{Schema: schemaResp.Schema}, &ProviderModel{
+		ApiKey:                    types.StringNull(),
+		ApiKeyCommand:             types.ListNull(types.StringType),
+		ApiKeyFile:                types.StringUnknown(),
+		Endpoint:                  types.StringValue(server.URL),
+		Headers:                   types.MapNull(types.StringType),
+		SkipCredentialsValidation: types.BoolNull(),
+	})
+	var dataSourceSchemaResp datasource.SchemaResponse
+	NewProjectsDataSource().Schema(ctx, datasource.SchemaRequest{}, &dataSourceSchemaResp)
+	dataSourceConfig := testValue(t, tfsdk.State{Schema: dataSourceSchemaResp.Schema}, &ProjectsDataSourceModel{
+		IncludeCounts: types.BoolNull(),
+		NamePrefix:    types.StringNull(),
+		NameRegex:     types.StringNull(),
+	})
+
+	// Without deferral support no client is handed out, data sources report
+	// the configuration is not known instead of calling the api
+	providerServer := providerserver.NewProtocol6(p)()
+	configureResp, err := providerServer.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: config})
+	assert.NoError(err)
+	assert.Empty(configureResp.Diagnostics)
+	readResp, err := providerServer.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		Config:   dataSourceConfig,
+		TypeName: "eventline_projects",
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"UnconfiguredProvider"}, diagnosticSummaries(readResp.Diagnostics, tfprotov6.DiagnosticSeverityError))
+
+	// Resources keep their state when refreshed
+	r := newTestResource(providerServer, NewProjectResource())
+	project := newProjectTestModel("test", types.BoolValue(true), types.BoolValue(false))
+	project.AdoptExisting = types.BoolValue(false)
+	project.Id = types.StringValue(ksuid.Generate().String())
+	state := testValue(t, r.state, project)
+	readResourceResp, err := providerServer.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		CurrentState: state,
+		TypeName:     r.typeName,
+	})
+	assert.NoError(err)
+	assert.Empty(readResourceResp.Diagnostics)
+	assert.Equal(state, readResourceResp.NewState)
+
+	// With deferral support data sources are deferred
+	providerServer = providerserver.NewProtocol6(New("test")())()
+	configureResp, err = providerServer.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		ClientCapabilities: &tfprotov6.ConfigureProviderClientCapabilities{DeferralAllowed: true},
+		Config:             config,
+	})
+	assert.NoError(err)
+	assert.Empty(configureResp.Diagnostics)
+	readResp, err = providerServer.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		ClientCapabilities: &tfprotov6.ReadDataSourceClientCapabilities{DeferralAllowed: true},
+		Config:             dataSourceConfig,
+		TypeName:           "eventline_projects",
+	})
+	assert.NoError(err)
+	assert.Empty(readResp.Diagnostics)
+	if assert.NotNil(readResp.Deferred) {
+		assert.Equal(tfprotov6.DeferredReasonProviderConfigUnknown, readResp.Deferred.Reason)
+	}
+
+	assert.Zero(requests)
+}
+
+func TestDescribeConnectivityError(t *testing.T) {
+	assert := assert.New(t)
+
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal([]byte("<html>"), &struct{}{}); !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected a json syntax error, got %v", err)
+	}
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"dns", &net.DNSError{Err: "no such host", Name: "eventline.example.com", IsNotFound: true}, "Unable to resolve the host"},
+		{"tls certificate", &url.Error{Op: "Get", URL: "https://eventline.example.com", Err: x509.UnknownAuthorityError{}}, "Unable to verify the TLS certificate"},
+		{"tls record", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, "does not use https on a plain http port"},
+		{"api key", &evcli.APIError{Message: "unauthorized", StatusCode: 401}, "The eventline api key was rejected"},
+		{"proxy", &evcli.ResponseError{StatusCode: 403}, "before reaching eventline, check your proxy configuration"},
+		{"not eventline", &evcli.ResponseError{StatusCode: 404}, "did not reply like an eventline api"},
+		{"not json", fmt.Errorf("cannot decode response body: %w", syntaxErr), "did not reply like an eventline api"},
+		{"other", errors.New("connection refused"), "Unable to reach the eventline endpoint"},
+	}
+	for _, tc := range testCases {
+		description := describeConnectivityError("https://eventline.example.com", tc.err)
+		assert.Contains(description, tc.expected, tc.name)
+		assert.Contains(description, `"https://eventline.example.com"`, tc.name)
+	}
+}
+
+func TestProviderRequestHeaders(t *testing.T) {
+	assert := assert.New(t)
+	headers, _ := types.MapValueFrom(context.Background(), types.StringType, map[string]string{"X-Proxy-Token": "token"})
+	f, server := newFakeEventlineWithHeaders(t, headers)
+	r := newTestResource(server, NewProjectResource())
+
+	prior, config, proposed := projectChange(nil, newProjectTestModel("test", types.BoolNull(), types.BoolNull()))
+	checkDiagnostics(t, r.apply(t, prior, config, proposed, nil))
+	assert.Equal([]string{"POST /projects"}, f.takeRequests())
+	assert.Equal("token", f.headers.Get("X-Proxy-Token"))
+	assert.Equal("terraform-provider-eventline/test terraform/1.14.0", f.headers.Get("User-Agent"))
+}
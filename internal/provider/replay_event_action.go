@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"git.adyxax.org/adyxax/terraform-provider-eventline/external/evcli"
+	"github.com/exograd/eventline/pkg/ksuid"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type ReplayEventAction struct {
+	client *evcli.Client
+}
+
+var _ action.Action = &ReplayEventAction{}              // Ensure provider defined types fully satisfy framework interfaces
+var _ action.ActionWithConfigure = &ReplayEventAction{} // Ensure provider defined types fully satisfy framework interfaces
+func NewReplayEventAction() action.Action {
+	return &ReplayEventAction{}
+}
+
+type ReplayEventActionModel struct {
+	EventId   types.String `tfsdk:"event_id"`
+	ProjectId types.String `tfsdk:"project_id"`
+}
+
+func (a *ReplayEventAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_replay_event"
+}
+
+func (a *ReplayEventAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"event_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the event to replay.",
+				Required:            true,
+			},
+			"project_id": schema.StringAttribute{
+				MarkdownDescription: "The identifier of the project the event is part of.",
+				Required:            true,
+			},
+		},
+		MarkdownDescription: "Replays an eventline event, triggering again the job it was processed by.",
+	}
+}
+
+func (a *ReplayEventAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	a.client, _ = req.ProviderData.(*evcli.Client)
+}
+
+func (a *ReplayEventAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data ReplayEventActionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var pid ksuid.KSUID
+	if err := pid.Parse(data.ProjectId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse project id, got error: %s", err))
+		return
+	}
+	var id ksuid.KSUID
+	if err := id.Parse(data.EventId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("KsuidParse", fmt.Sprintf("Unable to parse event id, got error: %s", err))
+		return
+	}
+	a.client.ProjectId = &pid
+	event, err := a.client.ReplayEvent(id.String())
+	if err != nil {
+		var e *evcli.APIError
+		if errors.As(err, &e) && e.Code == "unknown_event" {
+			resp.Diagnostics.AddError("ReplayEvent", fmt.Sprintf("Event %s does not exist in project %s", id, pid))
+			return
+		}
+		resp.Diagnostics.AddError("ReplayEvent", fmt.Sprintf("Unable to replay event, got error: %s", err))
+		return
+	}
+	resp.SendProgress(action.InvokeProgressEvent{Message: fmt.Sprintf("Event %s replayed as event %s", id, event.Id)})
+}
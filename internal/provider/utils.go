@@ -1,8 +1,13 @@
 package provider
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
+
+	"github.com/exograd/eventline/pkg/eventline"
 )
 
 func JSONRawDataEqual(a, b json.RawMessage) (bool, error) {
@@ -15,3 +20,54 @@ func JSONRawDataEqual(a, b json.RawMessage) (bool, error) {
 	}
 	return reflect.DeepEqual(j2, j), nil
 }
+
+// DecodeJobSpec decodes a json job specification, rejecting unknown top level
+// keys so that typos do not silently drop settings.
+func DecodeJobSpec(data string) (*eventline.JobSpec, error) {
+	var spec eventline.JobSpec
+	d := json.NewDecoder(bytes.NewReader([]byte(data)))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&spec); err != nil {
+		return nil, err
+	}
+	if spec.Name == "" {
+		return nil, fmt.Errorf("missing job name")
+	}
+	return &spec, nil
+}
+
+// JobSpecsEqual compares job specifications after applying the defaults the
+// eventline server fills in when deploying a job.
+func JobSpecsEqual(a, b *eventline.JobSpec) (bool, error) {
+	aData, err := json.Marshal(normalizeJobSpec(a))
+	if err != nil {
+		return false, err
+	}
+	bData, err := json.Marshal(normalizeJobSpec(b))
+	if err != nil {
+		return false, err
+	}
+	return JSONRawDataEqual(aData, bData)
+}
+
+func normalizeJobSpec(spec *eventline.JobSpec) *eventline.JobSpec {
+	s := *spec
+	if s.Runner == nil {
+		s.Runner = &eventline.JobRunner{Name: "local"}
+	} else {
+		runner := *s.Runner
+		s.Runner = &runner
+	}
+	if len(s.Runner.RawParameters) == 0 || string(s.Runner.RawParameters) == "null" {
+		s.Runner.RawParameters = json.RawMessage("{}")
+	}
+	s.Steps = make(eventline.Steps, len(spec.Steps))
+	for i, step := range spec.Steps {
+		st := *step
+		if st.Label == "" {
+			st.Label = "Step " + strconv.Itoa(i+1)
+		}
+		s.Steps[i] = &st
+	}
+	return &s
+}